@@ -0,0 +1,285 @@
+package combinator
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jojomi/textparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSepBy(t *testing.T) {
+	a := assert.New(t)
+
+	separator := Seq(String(","), Spaces())
+	numbers := SepBy(Int(), separator)
+	game := Map(Seq(
+		Map(String("Game "), func(string) []int { return nil }),
+		Map(Int(), func(id int) []int { return []int{id} }),
+		Map(String(": "), func(string) []int { return nil }),
+		numbers,
+	), func(parts [][]int) []int {
+		return append(parts[1], parts[3]...)
+	})
+
+	p := textparser.NewParser(`Game 22: 13,  14, 216, 90121. Irrelevant Info.`)
+	values, err := game.Parse(p)
+	a.Nil(err)
+	a.Equal([]int{22, 13, 14, 216, 90121}, values)
+	a.True(p.LookingAtString(". Irrelevant"))
+}
+
+func TestChoice(t *testing.T) {
+	a := assert.New(t)
+
+	keyword := Choice(String("true"), String("false"), String("null"))
+
+	p := textparser.NewParser("false")
+	v, err := keyword.Parse(p)
+	a.Nil(err)
+	a.Equal("false", v)
+	a.True(p.IsExhausted())
+
+	p = textparser.NewParser("nope")
+	_, err = keyword.Parse(p)
+	var combinatorErr *Error
+	a.True(errors.As(err, &combinatorErr))
+	a.Equal([]string{`"true"`, `"false"`, `"null"`}, combinatorErr.Expected)
+	a.Equal(0, p.CurrentIndex())
+	a.EqualError(err, `unexpected input at line 1, column 1, expected one of "true", "false", "null", found "nope"`)
+}
+
+func TestFurthestFailure(t *testing.T) {
+	a := assert.New(t)
+
+	// the item failing within Many got further than the close of Between
+	list := Between(String("["), Many(Seq(String("a"), String(";"))), String("]"))
+
+	p := textparser.NewParser("[a;a")
+	_, err := list.Parse(p)
+	var combinatorErr *Error
+	a.True(errors.As(err, &combinatorErr))
+	a.Equal(4, combinatorErr.Position.Offset)
+	a.Equal([]string{`";"`}, combinatorErr.Expected)
+
+	// failures at the same position are combined
+	p = textparser.NewParser("[a;a;")
+	_, err = list.Parse(p)
+	a.True(errors.As(err, &combinatorErr))
+	a.Equal(5, combinatorErr.Position.Offset)
+	a.Equal([]string{`"a"`, `"]"`}, combinatorErr.Expected)
+	a.Equal(0, p.CurrentIndex())
+}
+
+func TestOptionalLookaheadNot(t *testing.T) {
+	a := assert.New(t)
+
+	sign := Optional(Rune('-'))
+	p := textparser.NewParser("-5")
+	v, err := sign.Parse(p)
+	a.Nil(err)
+	a.Equal('-', *v)
+	v, err = sign.Parse(p)
+	a.Nil(err)
+	a.Nil(v)
+
+	digit := Lookahead(Int())
+	n, err := digit.Parse(p)
+	a.Nil(err)
+	a.Equal(5, n)
+	a.Equal(1, p.CurrentIndex())
+
+	_, err = Not(Int()).Parse(p)
+	a.EqualError(err, `unexpected input at line 1, column 2, expected not integer, found "5"`)
+
+	_, err = Not(String("x")).Parse(p)
+	a.Nil(err)
+
+	_, err = Many1(Rune('x')).Parse(p)
+	a.Error(err)
+	_, err = End().Parse(p.MustSkip(1))
+	a.Nil(err)
+}
+
+func TestLazy(t *testing.T) {
+	a := assert.New(t)
+
+	// nesting depth of parentheses
+	var depth Rule[int]
+	depth = Choice(
+		Map(Between(Rune('('), Lazy(func() Rule[int] { return depth }), Rune(')')), func(d int) int { return d + 1 }),
+		Map(String("x"), func(string) int { return 0 }),
+	)
+
+	for input, want := range map[string]int{"x": 0, "(x)": 1, "(((x)))": 3} {
+		p := textparser.NewParser(input)
+		a.Equal(want, depth.MustParse(p), input)
+		a.True(p.IsExhausted(), input)
+	}
+
+	_, err := depth.Parse(textparser.NewParser("((x)"))
+	a.ErrorContains(err, "column 5, expected ')'")
+
+	p := textparser.NewParser("(((x)))").SetLimits(textparser.Limits{MaxDepth: 2})
+	_, err = depth.Parse(p)
+	var limitErr *textparser.LimitError
+	a.True(errors.As(err, &limitErr))
+	a.Equal("depth", limitErr.Limit)
+	a.Equal(0, p.CurrentIndex())
+
+	// Memo does not count towards the limits on top of Lazy
+	var memoDepth Rule[int]
+	memoDepth = Memo(Choice(
+		Map(Between(Rune('('), Lazy(func() Rule[int] { return memoDepth }), Rune(')')), func(d int) int { return d + 1 }),
+		Map(String("x"), func(string) int { return 0 }),
+	))
+	p = textparser.NewParser("(((x)))").EnableMemo().SetLimits(textparser.Limits{MaxDepth: 3, MaxSteps: 3})
+	a.Equal(3, memoDepth.MustParse(p))
+}
+
+func TestLazy_concurrent(t *testing.T) {
+	var (
+		builds int
+		nested Rule[int]
+	)
+	nested = Choice(
+		Map(Between(Rune('('), Lazy(func() Rule[int] {
+			builds++
+			return nested
+		}), Rune(')')), func(d int) int { return d + 1 }),
+		Map(String("x"), func(string) int { return 0 }),
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, 3, nested.MustParse(textparser.NewParser("(((x)))")))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, builds)
+}
+
+func TestLimits(t *testing.T) {
+	a := assert.New(t)
+
+	// the shorter alternative must not win after the limit was hit in the first one
+	var nested Rule[string]
+	nested = Choice(
+		Map(Seq(String("("), Lazy(func() Rule[string] { return nested })), func([]string) string { return "nested" }),
+		String("("),
+	)
+	p := textparser.NewParser(strings.Repeat("(", 10)).SetLimits(textparser.Limits{MaxDepth: 3})
+	_, err := nested.Parse(p)
+	var limitErr *textparser.LimitError
+	a.True(errors.As(err, &limitErr))
+	a.EqualError(err, "limit exceeded at line 1, column 5: depth limit of 3 exceeded")
+	a.Equal(0, p.CurrentIndex())
+
+	// rules tolerating failures pass it on, too
+	for name, parse := range map[string]func(p *textparser.Parser) error{
+		"Many": func(p *textparser.Parser) error {
+			_, err := Many(Int()).Parse(p)
+			return err
+		},
+		"Optional": func(p *textparser.Parser) error {
+			_, err := Optional(Int()).Parse(p)
+			return err
+		},
+		"SepBy": func(p *textparser.Parser) error {
+			_, err := SepBy(Int(), String(",")).Parse(p.MustSkip(-2))
+			return err
+		},
+		"Not": func(p *textparser.Parser) error {
+			_, err := Not(Int()).Parse(p)
+			return err
+		},
+	} {
+		p = textparser.NewParser("1,12345").SetLimits(textparser.Limits{MaxTokenLength: 2}).MustSkip(2)
+		err = parse(p)
+		a.True(errors.As(err, &limitErr), name)
+		a.Equal("token length", limitErr.Limit, name)
+	}
+}
+
+func TestLabel(t *testing.T) {
+	a := assert.New(t)
+
+	letters := Map(Label("letters", Many(Rune('a'))), func([]rune) string { return "" })
+	rule := Seq(letters, String("c"))
+
+	// the label describes the rule at its start, not what ended the successful Many
+	p := textparser.NewParser("aab")
+	_, err := rule.Parse(p)
+	a.EqualError(err, `unexpected input at line 1, column 3, expected one of 'a', "c", found "b"`)
+
+	p = textparser.NewParser("b")
+	_, err = Label("letters", Many1(Rune('a'))).Parse(p)
+	a.EqualError(err, `unexpected input at line 1, column 1, expected letters, found "b"`)
+}
+
+func TestFunc(t *testing.T) {
+	a := assert.New(t)
+
+	hex := Label("hex number", Func(func(p *textparser.Parser) (int64, error) {
+		word, err := p.ReadWord()
+		if err != nil {
+			return 0, err
+		}
+		return strconv.ParseInt(word, 16, 64)
+	}))
+
+	p := textparser.NewParser("zz")
+	_, err := hex.Parse(p)
+	a.EqualError(err, `unexpected input at line 1, column 1, expected hex number, found "zz"`)
+	a.ErrorIs(err, strconv.ErrSyntax)
+	a.Equal(0, p.CurrentIndex())
+}
+
+func TestMemo(t *testing.T) {
+	a := assert.New(t)
+
+	// diff <- diff "-" int / int
+	var diff Rule[int]
+	diff = Memo(Choice(
+		Map(Seq(
+			Lazy(func() Rule[int] { return diff }),
+			Map(String("-"), func(string) int { return 0 }),
+			Int(),
+		), func(values []int) int {
+			return values[0] - values[2]
+		}),
+		Int(),
+	))
+
+	p := textparser.NewParser("10-2-3!").EnableMemo()
+	value, err := diff.Parse(p)
+	a.Nil(err)
+	a.Equal(5, value)
+	a.True(p.LookingAtString("!"))
+	a.Positive(p.MemoStats().Hits)
+
+	_, err = diff.Parse(p)
+	var failure *Error
+	a.True(errors.As(err, &failure))
+	a.Equal([]string{"integer"}, failure.Expected)
+}
+
+func TestError_render(t *testing.T) {
+	a := assert.New(t)
+
+	line := Seq(String("key"), String(": "), String("value"), String("\n"))
+	p := textparser.NewParser("key: value\nkey:value\n")
+	_, err := Seq(Map(line, func([]string) string { return "" }), Map(line, func([]string) string { return "" })).Parse(p)
+	a.EqualError(err, `unexpected input at line 2, column 4, expected ": ", found ":value\n"`)
+	a.Equal(`2:4: error: unexpected input, expected ": ", found ":value\n"
+  |
+2 | key:value
+  |    ^^^^^^^
+`, p.RenderError(err, textparser.DiagnosticOptions{}))
+}
@@ -0,0 +1,253 @@
+package combinator
+
+import (
+	"github.com/jojomi/textparser"
+)
+
+// Seq matches all rules in order.
+func Seq[T any](rules ...Rule[T]) Rule[[]T] {
+	return Rule[[]T]{
+		run: func(p *textparser.Parser) result[[]T] {
+			var (
+				mark    = p.Mark()
+				values  = make([]T, 0, len(rules))
+				failure *Error
+			)
+			for _, rule := range rules {
+				r := rule.run(p)
+				failure = merge(failure, r.failure)
+				if !r.ok {
+					p.Reset(mark)
+					return failed[[]T](failure)
+				}
+				values = append(values, r.value)
+			}
+			return success(values, failure)
+		},
+	}
+}
+
+// Choice tries the rules in order and returns the result of the first one that matches (ordered choice).
+func Choice[T any](rules ...Rule[T]) Rule[T] {
+	return Rule[T]{
+		run: func(p *textparser.Parser) result[T] {
+			var failure *Error
+			for _, rule := range rules {
+				r := rule.run(p)
+				failure = merge(failure, r.failure)
+				if r.ok {
+					return success(r.value, failure)
+				}
+				if r.aborted() {
+					break
+				}
+			}
+			return failed[T](failure)
+		},
+	}
+}
+
+// Many matches rule as often as possible, including zero times.
+func Many[T any](rule Rule[T]) Rule[[]T] {
+	return Rule[[]T]{
+		run: func(p *textparser.Parser) result[[]T] {
+			mark := p.Mark()
+			values, failure := many(p, rule, nil)
+			if failure.limitError() != nil {
+				p.Reset(mark)
+				return failed[[]T](failure)
+			}
+			return success(values, failure)
+		},
+	}
+}
+
+// Many1 matches rule as often as possible, but at least once.
+func Many1[T any](rule Rule[T]) Rule[[]T] {
+	return Rule[[]T]{
+		run: func(p *textparser.Parser) result[[]T] {
+			mark := p.Mark()
+			first := rule.run(p)
+			if !first.ok {
+				return failed[[]T](first.failure)
+			}
+			values, failure := many(p, rule, first.failure)
+			if failure.limitError() != nil {
+				p.Reset(mark)
+				return failed[[]T](failure)
+			}
+			return success(append([]T{first.value}, values...), failure)
+		},
+	}
+}
+
+// many repeats rule until it fails or stops consuming input.
+func many[T any](p *textparser.Parser, rule Rule[T], failure *Error) ([]T, *Error) {
+	values := make([]T, 0)
+	for {
+		start := p.CurrentIndex()
+		r := rule.run(p)
+		failure = merge(failure, r.failure)
+		if !r.ok {
+			return values, failure
+		}
+		values = append(values, r.value)
+
+		// no progress, repeating the rule would give the same empty match forever
+		if p.CurrentIndex() == start {
+			return values, failure
+		}
+	}
+}
+
+// Optional matches rule or nothing. The value is nil if rule did not match.
+func Optional[T any](rule Rule[T]) Rule[*T] {
+	return Rule[*T]{
+		label: rule.label,
+		run: func(p *textparser.Parser) result[*T] {
+			r := rule.run(p)
+			if r.aborted() {
+				return failed[*T](r.failure)
+			}
+			if !r.ok {
+				return success[*T](nil, r.failure)
+			}
+			return success(&r.value, r.failure)
+		},
+	}
+}
+
+// SepBy matches zero or more items separated by sep. A trailing separator is not consumed.
+func SepBy[T, S any](item Rule[T], sep Rule[S]) Rule[[]T] {
+	return Rule[[]T]{
+		run: func(p *textparser.Parser) result[[]T] {
+			start := p.Mark()
+			first := item.run(p)
+			if first.aborted() {
+				return failed[[]T](first.failure)
+			}
+			if !first.ok {
+				return success(make([]T, 0), first.failure)
+			}
+
+			var (
+				values  = []T{first.value}
+				failure = first.failure
+			)
+			for {
+				mark := p.Mark()
+				s := sep.run(p)
+				failure = merge(failure, s.failure)
+				if !s.ok {
+					break
+				}
+				i := item.run(p)
+				failure = merge(failure, i.failure)
+				if !i.ok || p.CurrentIndex() == mark.Index() {
+					p.Reset(mark)
+					break
+				}
+				values = append(values, i.value)
+			}
+			if failure.limitError() != nil {
+				p.Reset(start)
+				return failed[[]T](failure)
+			}
+			return success(values, failure)
+		},
+	}
+}
+
+// Between matches open, content and close in order and returns the content.
+func Between[O, T, C any](open Rule[O], content Rule[T], close Rule[C]) Rule[T] {
+	return Rule[T]{
+		run: func(p *textparser.Parser) result[T] {
+			mark := p.Mark()
+			o := open.run(p)
+			if !o.ok {
+				return failed[T](o.failure)
+			}
+			c := content.run(p)
+			failure := merge(o.failure, c.failure)
+			if !c.ok {
+				p.Reset(mark)
+				return failed[T](failure)
+			}
+			cl := close.run(p)
+			failure = merge(failure, cl.failure)
+			if !cl.ok {
+				p.Reset(mark)
+				return failed[T](failure)
+			}
+			return success(c.value, failure)
+		},
+	}
+}
+
+// Map converts the value of rule using f.
+func Map[T, U any](rule Rule[T], f func(T) U) Rule[U] {
+	return Rule[U]{
+		label: rule.label,
+		run: func(p *textparser.Parser) result[U] {
+			r := rule.run(p)
+			if !r.ok {
+				return failed[U](r.failure)
+			}
+			return success(f(r.value), r.failure)
+		},
+	}
+}
+
+// Lookahead matches rule without consuming any input.
+func Lookahead[T any](rule Rule[T]) Rule[T] {
+	return Rule[T]{
+		label: rule.label,
+		run: func(p *textparser.Parser) result[T] {
+			mark := p.Mark()
+			r := rule.run(p)
+			p.Reset(mark)
+			return r
+		},
+	}
+}
+
+// Not succeeds without consuming input if rule does not match at the current position.
+func Not[T any](rule Rule[T]) Rule[struct{}] {
+	return Rule[struct{}]{
+		run: func(p *textparser.Parser) result[struct{}] {
+			mark := p.Mark()
+			r := rule.run(p)
+			p.Reset(mark)
+			if r.aborted() {
+				return failed[struct{}](r.failure)
+			}
+			if r.ok {
+				return failed[struct{}](newError(p, "not "+rule.describe(), nil))
+			}
+			return success(struct{}{}, nil)
+		},
+	}
+}
+
+// Memo caches the results of rule by position, see textparser.Memoize. It only takes effect on parsers with
+// textparser.Parser.EnableMemo, where it also allows rule to be left-recursive by referring to itself through Lazy.
+func Memo[T any](rule Rule[T]) Rule[T] {
+	key := new(int)
+	return Rule[T]{
+		label: rule.label,
+		run: func(p *textparser.Parser) result[T] {
+			r, err := textparser.Memoize(p, key, func(p *textparser.Parser) (result[T], error) {
+				r := rule.run(p)
+				if !r.ok {
+					return r, r.failure
+				}
+				return r, nil
+			})
+			if err == nil || r.failure != nil {
+				return r
+			}
+			// the pending call of a left-recursive rule
+			return failed[T](newError(p, "", err))
+		},
+	}
+}
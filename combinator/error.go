@@ -0,0 +1,104 @@
+package combinator
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jojomi/textparser"
+)
+
+// Error is returned by Rule.Parse. It describes the furthest position any alternative reached before failing.
+type Error struct {
+	Position textparser.Position
+	// Expected lists what the alternatives were looking for at Position, in the order they were tried.
+	Expected []string
+	// Found is a short excerpt of the input at Position.
+	Found string
+	// Err is the error of the first primitive that failed at Position, if any.
+	Err error
+}
+
+func (x *Error) Error() string {
+	if limitErr := x.limitError(); limitErr != nil {
+		return fmt.Sprintf("%s at %s: %s", textparser.LimitExceeded, x.Position, limitErr)
+	}
+	return textparser.FormatFailure(x.Position, "", x.Expected, x.Found)
+}
+
+func (x *Error) ErrorPosition() textparser.Position {
+	return x.Position
+}
+
+func (x *Error) ErrorFound() string {
+	return x.Found
+}
+
+func (x *Error) ErrorMessage() string {
+	if limitErr := x.limitError(); limitErr != nil {
+		return fmt.Sprintf("%s: %s", textparser.LimitExceeded, limitErr)
+	}
+	return textparser.FormatFailureMessage("", x.Expected, x.Found)
+}
+
+func (x *Error) Unwrap() error {
+	return x.Err
+}
+
+var _ textparser.PositionedError = (*Error)(nil)
+
+const foundLength = 10
+
+// newError creates a failure at the current position of p.
+func newError(p *textparser.Parser, expected string, cause error) *Error {
+	var expectedList []string
+	if expected != "" {
+		expectedList = []string{expected}
+	}
+	return &Error{
+		Position: p.CurrentPosition(),
+		Expected: expectedList,
+		Found:    p.GetNextMax(foundLength),
+		Err:      cause,
+	}
+}
+
+// limitError returns the exceeded parser limit that caused the failure, nil if there is none.
+func (x *Error) limitError() *textparser.LimitError {
+	var limitErr *textparser.LimitError
+	if x == nil || !errors.As(x.Err, &limitErr) {
+		return nil
+	}
+	return limitErr
+}
+
+// merge returns the failure that got further. Failures at the same position are combined. An exceeded limit always
+// wins, as it ends the whole parse.
+func merge(a, b *Error) *Error {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.limitError() != nil:
+		return a
+	case b.limitError() != nil:
+		return b
+	case a.Position.Offset > b.Position.Offset:
+		return a
+	case b.Position.Offset > a.Position.Offset:
+		return b
+	}
+
+	merged := *a
+	merged.Expected = append([]string{}, a.Expected...)
+	for _, e := range b.Expected {
+		if !slices.Contains(merged.Expected, e) {
+			merged.Expected = append(merged.Expected, e)
+		}
+	}
+	if merged.Err == nil {
+		merged.Err = b.Err
+	}
+	return &merged
+}
@@ -0,0 +1,83 @@
+package combinator
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/jojomi/textparser"
+)
+
+// Func lifts a hand-written parse function into a rule. If f fails, the parser is reset to where f started, and the
+// failure is reported at that position. A *textparser.ParseError returned by f is used for the expected description.
+func Func[T any](f func(p *textparser.Parser) (T, error)) Rule[T] {
+	return Rule[T]{
+		run: func(p *textparser.Parser) result[T] {
+			mark := p.Mark()
+			value, err := f(p)
+			if err == nil {
+				return success(value, nil)
+			}
+			p.Reset(mark)
+
+			expected := err.Error()
+			var parseErr *textparser.ParseError
+			if errors.As(err, &parseErr) {
+				expected = parseErr.Expected
+			}
+			return failed[T](newError(p, expected, err))
+		},
+	}
+}
+
+// String matches the literal s.
+func String(s string) Rule[string] {
+	return Label(strconv.Quote(s), Func(func(p *textparser.Parser) (string, error) {
+		return s, p.SkipString(s)
+	}))
+}
+
+// Rune matches the rune r.
+func Rune(r rune) Rule[rune] {
+	return Label(strconv.QuoteRune(r), Func(func(p *textparser.Parser) (rune, error) {
+		if !p.LookingAtRune(r) {
+			return 0, errors.New("rune mismatch")
+		}
+		return p.MustReadRune(), nil
+	}))
+}
+
+// Int matches an integer, see textparser.Parser.ReadInt.
+func Int() Rule[int] {
+	return Label("integer", Func(func(p *textparser.Parser) (int, error) {
+		return p.ReadInt()
+	}))
+}
+
+// Word matches a word, see textparser.Parser.ReadWord.
+func Word() Rule[string] {
+	return Label("word", Func(func(p *textparser.Parser) (string, error) {
+		return p.ReadWord()
+	}))
+}
+
+// Spaces skips any number of spaces, including none. It never fails.
+func Spaces() Rule[string] {
+	return Func(func(p *textparser.Parser) (string, error) {
+		start := p.CurrentIndex()
+		_ = p.SkipSpaces()
+		if p.CurrentIndex() == start {
+			return "", nil
+		}
+		return p.Extract(start, p.CurrentIndex())
+	})
+}
+
+// End matches the end of input.
+func End() Rule[struct{}] {
+	return Label("end of input", Func(func(p *textparser.Parser) (struct{}, error) {
+		if p.HasMore() {
+			return struct{}{}, errors.New("input left")
+		}
+		return struct{}{}, nil
+	}))
+}
@@ -0,0 +1,113 @@
+// Package combinator builds parsers from small rules running on a textparser.Parser.
+//
+// Every rule either succeeds and consumes input or fails and leaves the parser where it was, so rules can be combined
+// with ordered choice freely. Failures report the furthest position any alternative reached together with the set of
+// things that were expected there. A failure caused by an exceeded textparser.Limits ends the whole parse instead: no
+// further alternatives are tried.
+package combinator
+
+import (
+	"sync"
+
+	"github.com/jojomi/textparser"
+)
+
+// Rule parses a value of type T. Create rules with the primitives of this package (String, Int, Func, …) and combine
+// them with Seq, Choice, Many and friends.
+type Rule[T any] struct {
+	label string
+	run   func(p *textparser.Parser) result[T]
+}
+
+// result is the outcome of running a rule. On success, failure may hold the furthest failure that was tolerated on
+// the way, e.g. the item that ended Many, so that enclosing rules can still report it.
+type result[T any] struct {
+	value   T
+	ok      bool
+	failure *Error
+}
+
+// Parse runs the rule at the current position of p. On failure, the parser is left unchanged and the returned error
+// is an *Error.
+func (x Rule[T]) Parse(p *textparser.Parser) (T, error) {
+	r := x.run(p)
+	if !r.ok {
+		return r.value, r.failure
+	}
+	return r.value, nil
+}
+
+// MustParse is like Parse, but panics on failure.
+func (x Rule[T]) MustParse(p *textparser.Parser) T {
+	value, err := x.Parse(p)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// Label replaces what the rule reports as expected when it fails without consuming input, e.g. "key" instead of
+// a list of all the letters a key could start with. Failures the rule tolerated while succeeding are kept as they are.
+func Label[T any](label string, rule Rule[T]) Rule[T] {
+	return Rule[T]{
+		label: label,
+		run: func(p *textparser.Parser) result[T] {
+			start := p.CurrentIndex()
+			r := rule.run(p)
+			// a failed rule is back at start, where the label applies
+			if !r.ok && p.CurrentIndex() == start && r.failure.Position.Offset <= start {
+				r.failure = newError(p, label, r.failure.Err)
+			}
+			return r
+		},
+	}
+}
+
+func success[T any](value T, failure *Error) result[T] {
+	return result[T]{value: value, ok: true, failure: failure}
+}
+
+func failed[T any](failure *Error) result[T] {
+	return result[T]{failure: failure}
+}
+
+// aborted determines if the rule failed because of an exceeded limit, which must not be recovered from.
+func (x result[T]) aborted() bool {
+	return !x.ok && x.failure.limitError() != nil
+}
+
+// describe returns the label of a rule or a generic description.
+func (x Rule[T]) describe() string {
+	if x.label != "" {
+		return x.label
+	}
+	return "input"
+}
+
+// Lazy defers building a rule until it is first run, which allows recursive grammars:
+//
+//	var value Rule[int]
+//	value = Choice(Int(), Between(String("("), Lazy(func() Rule[int] { return value }), String(")")))
+//
+// build is called once, even if the rule is run by several goroutines at the same time.
+func Lazy[T any](build func() Rule[T]) Rule[T] {
+	var (
+		once sync.Once
+		rule Rule[T]
+	)
+	return Rule[T]{
+		run: func(p *textparser.Parser) result[T] {
+			once.Do(func() {
+				rule = build()
+			})
+			if err := p.Step(); err != nil {
+				return failed[T](newError(p, "", err))
+			}
+			if err := p.EnterNesting(); err != nil {
+				return failed[T](newError(p, "", err))
+			}
+			defer p.ExitNesting()
+			return rule.run(p)
+		},
+	}
+}
@@ -0,0 +1,124 @@
+package textparser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[1;31m"
+	ansiBlue  = "\x1b[1;34m"
+)
+
+// DiagnosticOptions configures RenderError.
+type DiagnosticOptions struct {
+	// FileName is printed in front of line and column, if set.
+	FileName string
+	// ContextLines is the number of source lines shown before and after the offending lines.
+	ContextLines int
+	// Color enables ANSI escape sequences for terminal output.
+	Color bool
+}
+
+// RenderError formats err like a compiler diagnostic: file name, line and column, the offending source lines and
+// a caret underline below the erroneous span. For a PositionedError like ParseError the span starts at its position
+// and covers the input found there, other errors are reported at the current position.
+func (x *Parser) RenderError(err error, opts DiagnosticOptions) string {
+	var (
+		start         = x.position
+		length        = 1
+		message       = err.Error()
+		positionedErr PositionedError
+	)
+	if errors.As(err, &positionedErr) {
+		start = positionedErr.ErrorPosition().Offset
+		length = max(1, utf8.RuneCountInString(positionedErr.ErrorFound()))
+		message = positionedErr.ErrorMessage()
+	}
+
+	lineStarts := x.lineIndex()
+	var (
+		startPos  = x.PositionAt(start)
+		endPos    = x.PositionAt(max(start, min(start+length, x.length())-1))
+		firstLine = max(1, startPos.Line-opts.ContextLines)
+		lastLine  = min(len(lineStarts), endPos.Line+opts.ContextLines)
+		gutter    = len(strconv.Itoa(lastLine))
+		b         strings.Builder
+	)
+
+	color := func(code, s string) string {
+		if !opts.Color {
+			return s
+		}
+		return code + s + ansiReset
+	}
+
+	// header
+	location := fmt.Sprintf("%d:%d", startPos.Line, startPos.Column)
+	if opts.FileName != "" {
+		location = opts.FileName + ":" + location
+	}
+	fmt.Fprintf(&b, "%s: %s %s\n", color(ansiBold, location), color(ansiRed, "error:"), color(ansiBold, message))
+	fmt.Fprintf(&b, "%s\n", color(ansiBlue, strings.Repeat(" ", gutter)+" |"))
+
+	// source lines, with underlines for the ones that are part of the span
+	for line := firstLine; line <= lastLine; line++ {
+		source := x.lineContent(line)
+		fmt.Fprintf(&b, "%s %s\n", color(ansiBlue, fmt.Sprintf("%*d |", gutter, line)), source)
+
+		if line < startPos.Line || line > endPos.Line {
+			continue
+		}
+		from, to := 1, utf8.RuneCountInString(source)
+		if line == startPos.Line {
+			from = startPos.Column
+		}
+		if line == endPos.Line {
+			to = endPos.Column
+		}
+
+		fmt.Fprintf(&b, "%s %s%s\n",
+			color(ansiBlue, strings.Repeat(" ", gutter)+" |"),
+			underlinePadding(source, from-1),
+			color(ansiRed, strings.Repeat("^", max(1, to-from+1))),
+		)
+	}
+
+	return b.String()
+}
+
+// lineContent returns the content of the 1-based line without its line break.
+func (x *Parser) lineContent(line int) string {
+	lineStarts := x.lineIndex()
+	start := lineStarts[line-1]
+	end := x.length()
+	if line < len(lineStarts) {
+		end = lineStarts[line] - 1
+	}
+	return strings.TrimSuffix(x.slice(start, end), "\r")
+}
+
+// underlinePadding returns whitespace as wide as the first runeCount runes of source, keeping tabs so the underline
+// lines up with the source line.
+func underlinePadding(source string, runeCount int) string {
+	var b strings.Builder
+	for _, r := range source {
+		if runeCount == 0 {
+			break
+		}
+		if r == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteRune(' ')
+		}
+		runeCount--
+	}
+	// positions beyond the line content, e.g. at the end of input
+	b.WriteString(strings.Repeat(" ", runeCount))
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package textparser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParser_RenderError(t *testing.T) {
+	input := "name: Tom\nrole: CEO\nos: Linux"
+
+	tests := []struct {
+		name  string
+		parse func(p *Parser) error
+		opts  DiagnosticOptions
+		want  string
+	}{
+		{
+			name: "with context",
+			parse: func(p *Parser) error {
+				return p.MustSkipRestOfLine().MustSkipNewlines().MustSkipString("role: ").SkipString("CTO")
+			},
+			opts: DiagnosticOptions{FileName: "people.txt", ContextLines: 1},
+			want: `people.txt:2:7: error: unexpected input, expected "CTO", found "CEO"
+  |
+1 | name: Tom
+2 | role: CEO
+  |       ^^^
+3 | os: Linux
+`,
+		},
+		{
+			name: "color",
+			parse: func(p *Parser) error {
+				return p.SkipString("x")
+			},
+			opts: DiagnosticOptions{Color: true},
+			want: "\x1b[1m1:1\x1b[0m: \x1b[1;31merror:\x1b[0m \x1b[1munexpected input, expected \"x\", found \"n\"\x1b[0m\n" +
+				"\x1b[1;34m  |\x1b[0m\n" +
+				"\x1b[1;34m1 |\x1b[0m name: Tom\n" +
+				"\x1b[1;34m  |\x1b[0m \x1b[1;31m^\x1b[0m\n",
+		},
+		{
+			name: "end of input",
+			parse: func(p *Parser) error {
+				_, err := p.ReadToMatchingRuneSkipDelims('n', ')')
+				return err
+			},
+			want: `3:10: error: unexpected end of input, expected closing ')'
+  |
+3 | os: Linux
+  |          ^
+`,
+		},
+		{
+			name: "other error",
+			parse: func(p *Parser) error {
+				p.MustSkip(2)
+				return errors.New("unknown name")
+			},
+			want: `1:3: error: unknown name
+  |
+1 | name: Tom
+  |   ^
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(input)
+			err := tt.parse(p)
+			assert.Equal(t, tt.want, p.RenderError(err, tt.opts))
+		})
+	}
+}
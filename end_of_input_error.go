@@ -1,7 +1,14 @@
 package textparser
 
+import "io"
+
 type EndOfInputError struct{}
 
 func (x EndOfInputError) Error() string {
 	return "end of input"
 }
+
+// Is makes EndOfInputError match io.EOF, too.
+func (x EndOfInputError) Is(target error) bool {
+	return target == io.EOF
+}
@@ -0,0 +1,238 @@
+// Package expr parses operator expressions on a textparser.Parser using precedence climbing (Pratt parsing).
+//
+// A Grammar declares the operators along with their binding power: the higher it is, the tighter the operator binds.
+// Values are built by callbacks, so a grammar can evaluate expressions directly or build a tree, see Node.
+package expr
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jojomi/textparser"
+)
+
+// Assoc is the associativity of an infix operator.
+type Assoc int
+
+const (
+	// Left groups a-b-c as (a-b)-c.
+	Left Assoc = iota
+	// Right groups a^b^c as a^(b^c).
+	Right
+)
+
+type operator[T any] struct {
+	symbol string
+	power  int
+	assoc  Assoc
+	unary  func(op string, operand T) T
+	binary func(op string, left, right T) T
+}
+
+type group struct {
+	open, close string
+}
+
+// Grammar parses expressions of type T. Create it using New and declare the operators using the chainable methods.
+type Grammar[T any] struct {
+	atom       func(p *textparser.Parser) (T, error)
+	prefix     []operator[T]
+	infix      []operator[T]
+	postfix    []operator[T]
+	groups     []group
+	whitespace *textparser.RuneSet
+}
+
+// New creates a grammar reading operands using atom. By default, spaces, tabs and newlines are allowed around
+// operators and operands.
+func New[T any](atom func(p *textparser.Parser) (T, error)) *Grammar[T] {
+	return &Grammar[T]{
+		atom:       atom,
+		whitespace: textparser.RuneSetOf(' ', '\t', '\n', '\r'),
+	}
+}
+
+// Whitespace sets the runes skipped around operators and operands, nil to skip nothing (chainable).
+func (x *Grammar[T]) Whitespace(set *textparser.RuneSet) *Grammar[T] {
+	x.whitespace = set
+	return x
+}
+
+// Prefix declares a prefix operator like the negation in -a (chainable). Its operand extends as long as the
+// operators in it bind tighter than power.
+func (x *Grammar[T]) Prefix(symbol string, power int, build func(op string, operand T) T) *Grammar[T] {
+	x.prefix = addOperator(x.prefix, operator[T]{symbol: symbol, power: power, unary: build})
+	return x
+}
+
+// Infix declares a binary operator like the addition in a+b (chainable).
+func (x *Grammar[T]) Infix(symbol string, power int, assoc Assoc, build func(op string, left, right T) T) *Grammar[T] {
+	x.infix = addOperator(x.infix, operator[T]{symbol: symbol, power: power, assoc: assoc, binary: build})
+	return x
+}
+
+// Postfix declares a postfix operator like the factorial in a! (chainable).
+func (x *Grammar[T]) Postfix(symbol string, power int, build func(op string, operand T) T) *Grammar[T] {
+	x.postfix = addOperator(x.postfix, operator[T]{symbol: symbol, power: power, unary: build})
+	return x
+}
+
+// Group declares delimiters enclosing a nested expression, like parentheses (chainable). The nested expression's
+// value is used as is.
+func (x *Grammar[T]) Group(open, close string) *Grammar[T] {
+	x.groups = append(x.groups, group{open: open, close: close})
+	return x
+}
+
+// Parse reads an expression at the current position of p. Whitespace after the expression is not consumed. On
+// failure, the parser is reset to where it started.
+func (x *Grammar[T]) Parse(p *textparser.Parser) (T, error) {
+	var result T
+	err := p.Attempt(func(p *textparser.Parser) error {
+		var err error
+		result, err = x.parse(p, 0)
+		return err
+	})
+	return result, err
+}
+
+func (x *Grammar[T]) MustParse(p *textparser.Parser) T {
+	value, err := x.Parse(p)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// parse reads an expression containing operators binding at least as tight as minPower.
+func (x *Grammar[T]) parse(p *textparser.Parser, minPower int) (T, error) {
+	left, err := x.parseOperand(p)
+	if err != nil {
+		return left, err
+	}
+
+	for {
+		mark := p.Mark()
+		x.skipWhitespace(p)
+
+		// the longer operator wins, so an infix != is not taken for a postfix !
+		postfix, isPostfix := x.match(p, x.postfix)
+		op, ok := x.match(p, x.infix)
+		if isPostfix && (!ok || len(postfix.symbol) >= len(op.symbol)) {
+			if postfix.power < minPower {
+				p.Reset(mark)
+				return left, nil
+			}
+			p.MustSkip(utf8.RuneCountInString(postfix.symbol))
+			left = postfix.unary(postfix.symbol, left)
+			continue
+		}
+
+		if !ok || op.power < minPower {
+			p.Reset(mark)
+			return left, nil
+		}
+		p.MustSkip(utf8.RuneCountInString(op.symbol))
+
+		rightPower := op.power + 1
+		if op.assoc == Right {
+			rightPower = op.power
+		}
+		right, err := x.parseRight(p, rightPower)
+		if err != nil {
+			return left, err
+		}
+		left = op.binary(op.symbol, left, right)
+	}
+}
+
+// parseRight reads the right operand of an infix operator. It counts as a level of nesting, as chains of right
+// associative operators recurse once per operator.
+func (x *Grammar[T]) parseRight(p *textparser.Parser, minPower int) (T, error) {
+	if err := p.EnterNesting(); err != nil {
+		var zero T
+		return zero, err
+	}
+	defer p.ExitNesting()
+	return x.parse(p, minPower)
+}
+
+// parseOperand reads a prefix operator with its operand, a group or an atom.
+func (x *Grammar[T]) parseOperand(p *textparser.Parser) (T, error) {
+	var zero T
+	if err := p.Step(); err != nil {
+		return zero, err
+	}
+	if err := p.EnterNesting(); err != nil {
+		return zero, err
+	}
+	defer p.ExitNesting()
+
+	x.skipWhitespace(p)
+
+	if op, ok := x.match(p, x.prefix); ok {
+		p.MustSkip(utf8.RuneCountInString(op.symbol))
+		operand, err := x.parse(p, op.power)
+		if err != nil {
+			return operand, err
+		}
+		return op.unary(op.symbol, operand), nil
+	}
+
+	for _, g := range x.groups {
+		if !p.LookingAtString(g.open) {
+			continue
+		}
+		p.MustSkip(utf8.RuneCountInString(g.open))
+		value, err := x.parse(p, 0)
+		if err != nil {
+			return value, err
+		}
+		x.skipWhitespace(p)
+		return value, p.SkipString(g.close)
+	}
+
+	return x.atom(p)
+}
+
+// match finds the longest operator at the current position. Operators ending in a letter or digit, like "and", only
+// match if they are not followed by another one.
+func (x *Grammar[T]) match(p *textparser.Parser, operators []operator[T]) (operator[T], bool) {
+	for _, op := range operators {
+		if !p.LookingAtString(op.symbol) {
+			continue
+		}
+		last, _ := utf8.DecodeLastRuneInString(op.symbol)
+		if isWordRune(last) {
+			mark := p.Mark()
+			p.MustSkip(utf8.RuneCountInString(op.symbol))
+			next, err := p.GetNextRune()
+			p.Reset(mark)
+			if err == nil && isWordRune(next) {
+				continue
+			}
+		}
+		return op, true
+	}
+	return operator[T]{}, false
+}
+
+func (x *Grammar[T]) skipWhitespace(p *textparser.Parser) {
+	if x.whitespace != nil {
+		p.SkipSet(x.whitespace)
+	}
+}
+
+// addOperator adds op, keeping operators sorted by descending length so the longest one matches first.
+func addOperator[T any](operators []operator[T], op operator[T]) []operator[T] {
+	operators = append(operators, op)
+	sort.SliceStable(operators, func(i, j int) bool {
+		return len(operators[i].symbol) > len(operators[j].symbol)
+	})
+	return operators
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
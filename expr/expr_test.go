@@ -0,0 +1,161 @@
+package expr
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/jojomi/textparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTreeGrammar() *Grammar[*Node] {
+	ident := func(p *textparser.Parser) (string, error) {
+		return p.ReadWord()
+	}
+	return New(Atom(func(p *textparser.Parser) (string, error) {
+		value := p.ReadSet(textparser.MustCompileRuneSet(`[a-z0-9]`))
+		if value == "" {
+			return ident(p)
+		}
+		return value, nil
+	})).
+		Infix("or", 1, Left, Infix).
+		Infix("and", 2, Left, Infix).
+		Prefix("not", 3, Prefix).
+		Infix("==", 5, Left, Infix).
+		Infix("!=", 5, Left, Infix).
+		Infix("+", 10, Left, Infix).
+		Infix("-", 10, Left, Infix).
+		Infix("*", 20, Left, Infix).
+		Infix("^", 30, Right, Infix).
+		Prefix("-", 25, Prefix).
+		Postfix("!", 40, Postfix).
+		Group("(", ")")
+}
+
+func TestGrammar_Parse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		want     string
+		wantRest string
+	}{
+		{
+			name:  "atom",
+			input: "a",
+			want:  "a",
+		},
+		{
+			name:  "precedence",
+			input: "a + b * c - d",
+			want:  "((a + (b * c)) - d)",
+		},
+		{
+			name:  "right associative",
+			input: "a ^ b ^ c",
+			want:  "(a ^ (b ^ c))",
+		},
+		{
+			name:  "prefix binds weaker than power",
+			input: "-a ^ 2 * b",
+			want:  "((-(a ^ 2)) * b)",
+		},
+		{
+			name:  "postfix",
+			input: "-n! * 2",
+			want:  "((-(n!)) * 2)",
+		},
+		{
+			name:  "longest operator wins",
+			input: "n != m",
+			want:  "(n != m)",
+		},
+		{
+			name:  "groups",
+			input: "(a + b) * ( c )",
+			want:  "((a + b) * c)",
+		},
+		{
+			name:  "word operators",
+			input: "not a and b == c or android",
+			want:  "(((not a) and (b == c)) or android)",
+		},
+		{
+			name:     "stops at unknown input",
+			input:    "a + b ; c",
+			want:     "(a + b)",
+			wantRest: " ; c",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := textparser.NewParser(tt.input)
+			node, err := newTreeGrammar().Parse(p)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, node.String())
+			assert.Equal(t, tt.wantRest, p.Remaining())
+		})
+	}
+}
+
+func TestGrammar_ParseErrors(t *testing.T) {
+	a := assert.New(t)
+
+	p := textparser.NewParser("(a + b")
+	_, err := newTreeGrammar().Parse(p)
+	a.ErrorIs(err, textparser.EndOfInputError{})
+	a.Equal(0, p.CurrentIndex())
+
+	p = textparser.NewParser("a * ")
+	_, err = newTreeGrammar().Parse(p)
+	a.NotNil(err)
+	a.Equal(0, p.CurrentIndex())
+}
+
+func TestGrammar_ParseLimits(t *testing.T) {
+	a := assert.New(t)
+
+	input := strings.Repeat("(", 100) + "a" + strings.Repeat(")", 100)
+	p := textparser.NewParser(input).SetLimits(textparser.Limits{MaxDepth: 50})
+	_, err := newTreeGrammar().Parse(p)
+	var limitErr *textparser.LimitError
+	a.True(errors.As(err, &limitErr))
+	a.Equal("depth", limitErr.Limit)
+	a.Equal(0, p.CurrentIndex())
+
+	p = textparser.NewParser(input).SetLimits(textparser.Limits{MaxDepth: 200})
+	node, err := newTreeGrammar().Parse(p)
+	a.Nil(err)
+	a.Equal("a", node.Text)
+
+	// right associative operators nest, too
+	input = "a" + strings.Repeat(" ^ a", 10000)
+	p = textparser.NewParser(input).SetLimits(textparser.Limits{MaxDepth: 10})
+	_, err = newTreeGrammar().Parse(p)
+	a.True(errors.As(err, &limitErr))
+	a.Equal("depth", limitErr.Limit)
+	a.Equal(0, p.CurrentIndex())
+
+	// left associative ones don't
+	p = textparser.NewParser(strings.Repeat("a + ", 10000) + "a").SetLimits(textparser.Limits{MaxDepth: 10})
+	_, err = newTreeGrammar().Parse(p)
+	a.Nil(err)
+}
+
+func TestGrammar_evaluate(t *testing.T) {
+	a := assert.New(t)
+
+	calc := New((*textparser.Parser).ReadFloat).
+		Infix("+", 1, Left, func(_ string, l, r float64) float64 { return l + r }).
+		Infix("-", 1, Left, func(_ string, l, r float64) float64 { return l - r }).
+		Infix("*", 2, Left, func(_ string, l, r float64) float64 { return l * r }).
+		Infix("/", 2, Left, func(_ string, l, r float64) float64 { return l / r }).
+		Infix("**", 3, Right, func(_ string, l, r float64) float64 { return math.Pow(l, r) }).
+		Group("(", ")")
+
+	a.Equal(-1.0, calc.MustParse(textparser.NewParser("1 - 2 * (3 - 2)")))
+	a.Equal(512.0, calc.MustParse(textparser.NewParser("2 ** 3 ** 2")))
+	a.Equal(2.5, calc.MustParse(textparser.NewParser("10/2/2")))
+}
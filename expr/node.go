@@ -0,0 +1,90 @@
+package expr
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jojomi/textparser"
+)
+
+// NodeKind is the type of a Node.
+type NodeKind int
+
+const (
+	AtomNode NodeKind = iota
+	PrefixNode
+	InfixNode
+	PostfixNode
+)
+
+// Node is a generic syntax tree node. Use the builders Atom, Prefix, Infix and Postfix to create a Grammar[*Node]:
+//
+//	g := expr.New(expr.Atom(ident)).
+//		Infix("+", 10, expr.Left, expr.Infix).
+//		Prefix("-", 20, expr.Prefix)
+type Node struct {
+	Kind NodeKind
+	// Op is the operator, empty for atoms.
+	Op string
+	// Text is the input of an atom.
+	Text string
+	// Operands are the operands of an operator, one for prefix and postfix operators, two for infix operators.
+	Operands []*Node
+}
+
+// String returns the tree in a fully parenthesized form like (a + (-b)) or ((not a) and b).
+func (x *Node) String() string {
+	var b strings.Builder
+	x.write(&b)
+	return b.String()
+}
+
+func (x *Node) write(b *strings.Builder) {
+	switch x.Kind {
+	case AtomNode:
+		b.WriteString(x.Text)
+	case PrefixNode:
+		b.WriteString("(" + x.Op)
+		if last, _ := utf8.DecodeLastRuneInString(x.Op); isWordRune(last) {
+			b.WriteString(" ")
+		}
+		x.Operands[0].write(b)
+		b.WriteString(")")
+	case PostfixNode:
+		b.WriteString("(")
+		x.Operands[0].write(b)
+		b.WriteString(x.Op + ")")
+	case InfixNode:
+		b.WriteString("(")
+		x.Operands[0].write(b)
+		b.WriteString(" " + x.Op + " ")
+		x.Operands[1].write(b)
+		b.WriteString(")")
+	}
+}
+
+// Atom wraps a function reading the text of an atom into an atom parser for a Grammar[*Node].
+func Atom(read func(p *textparser.Parser) (string, error)) func(p *textparser.Parser) (*Node, error) {
+	return func(p *textparser.Parser) (*Node, error) {
+		text, err := read(p)
+		if err != nil {
+			return nil, err
+		}
+		return &Node{Kind: AtomNode, Text: text}, nil
+	}
+}
+
+// Prefix builds a node for a prefix operator.
+func Prefix(op string, operand *Node) *Node {
+	return &Node{Kind: PrefixNode, Op: op, Operands: []*Node{operand}}
+}
+
+// Infix builds a node for an infix operator.
+func Infix(op string, left, right *Node) *Node {
+	return &Node{Kind: InfixNode, Op: op, Operands: []*Node{left, right}}
+}
+
+// Postfix builds a node for a postfix operator.
+func Postfix(op string, operand *Node) *Node {
+	return &Node{Kind: PostfixNode, Op: op, Operands: []*Node{operand}}
+}
@@ -0,0 +1,112 @@
+package textparser
+
+import (
+	"unicode/utf8"
+)
+
+// runeBlockSize is the distance in runes between the byte offsets stored for non-ASCII input.
+const runeBlockSize = 64
+
+// The input is kept as the original UTF-8 string, while the public API works with rune indices. For ASCII input both
+// are the same. For other input, the byte offset of every runeBlockSize-th rune is stored, so a rune index can be
+// converted by decoding at most runeBlockSize-1 runes. The last conversion within each block is cached, so sequential
+// access is cheap, and returning to an earlier position, like backtracking parsers do, only rescans the runes between
+// it and the last position used in its block.
+
+// blockCursor is the last rune index converted within a block along with its byte offset.
+type blockCursor struct {
+	index  int
+	offset int
+}
+
+// indexInput counts the runes of the input and stores the block offsets, once.
+func (x *Parser) indexInput() {
+	if x.indexed {
+		return
+	}
+	x.indexed = true
+
+	ascii := true
+	for i := 0; i < len(x.input); i++ {
+		if x.input[i] >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		x.runeCount = len(x.input)
+		return
+	}
+
+	var (
+		offsets = make([]int, 0, len(x.input)/runeBlockSize+1)
+		count   = 0
+	)
+	for offset := range x.input {
+		if count%runeBlockSize == 0 {
+			offsets = append(offsets, offset)
+		}
+		count++
+	}
+	x.runeCount = count
+	x.blockOffsets = offsets
+	x.blockCursors = make([]blockCursor, len(offsets))
+	for block, offset := range offsets {
+		x.blockCursors[block] = blockCursor{index: block * runeBlockSize, offset: offset}
+	}
+}
+
+// length returns the number of runes in the input.
+func (x *Parser) length() int {
+	x.indexInput()
+	return x.runeCount
+}
+
+// byteOffset converts the rune index to a byte offset. Indices at or beyond the end map to the input length.
+func (x *Parser) byteOffset(index int) int {
+	x.indexInput()
+	if x.blockOffsets == nil {
+		return min(index, len(x.input))
+	}
+	if index >= x.runeCount {
+		return len(x.input)
+	}
+
+	var (
+		block  = index / runeBlockSize
+		cursor = &x.blockCursors[block]
+		pos    = cursor.index
+		offset = cursor.offset
+	)
+	// walk from the block start or the cursor, whichever is closer
+	if start := block * runeBlockSize; index-start < pos-index {
+		pos, offset = start, x.blockOffsets[block]
+	}
+	for ; pos < index; pos++ {
+		_, size := utf8.DecodeRuneInString(x.input[offset:])
+		offset += size
+	}
+	for ; pos > index; pos-- {
+		_, size := utf8.DecodeLastRuneInString(x.input[:offset])
+		offset -= size
+	}
+
+	cursor.index, cursor.offset = index, offset
+	return offset
+}
+
+// runeAt returns the rune at index, which must be within the input.
+func (x *Parser) runeAt(index int) rune {
+	offset := x.byteOffset(index)
+	if offset < len(x.input) && x.input[offset] < utf8.RuneSelf {
+		return rune(x.input[offset])
+	}
+	r, _ := utf8.DecodeRuneInString(x.input[offset:])
+	return r
+}
+
+// slice returns the input between the rune indices start and end without copying.
+func (x *Parser) slice(start, end int) string {
+	startOffset := x.byteOffset(start)
+	return x.input[startOffset:x.byteOffset(end)]
+}
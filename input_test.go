@@ -0,0 +1,50 @@
+package textparser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParser_inputIndex(t *testing.T) {
+	a := assert.New(t)
+
+	// spans several blocks with runes of different byte lengths
+	input := strings.Repeat("aö€😀", 50)
+	runes := []rune(input)
+	p := NewParser(input)
+
+	a.Equal(len(runes), p.length())
+	for i := range runes {
+		a.Equal(runes[i], p.runeAt(i))
+	}
+	// backwards after the cache moved to the end
+	for i := len(runes) - 1; i >= 0; i -= 7 {
+		a.Equal(string(runes[i:]), p.slice(i, len(runes)))
+	}
+	a.Equal(len(input), p.byteOffset(len(runes)))
+
+	// backtracking within and across blocks, like after Reset
+	for _, i := range []int{130, 70, 129, 10, 199, 65, 131, 0, 63, 64} {
+		a.Equal(len(string(runes[:i])), p.byteOffset(i), "index %d", i)
+	}
+
+	ascii := NewParser("abc")
+	a.Equal(3, ascii.length())
+	a.Nil(ascii.blockOffsets)
+	a.Equal("bc", ascii.slice(1, 3))
+}
+
+func TestParser_ExtractDoesNotAllocate(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser(strings.Repeat("Grüße, ", 100))
+	p.MustSkip(20)
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = p.MustExtract(10, 500)
+		_ = p.LookingAtString("Grüße")
+		_ = p.Remaining()
+	})
+	a.Equal(0.0, allocs)
+}
@@ -0,0 +1,230 @@
+// Package lexer splits the input of a textparser.Parser into tokens.
+//
+// Rules pair a token kind with a Matcher. At every position, all rules are tried and the longest match wins. Ties are
+// broken by the higher priority, then by the order in which the rules were added. Skip rules take part in the same
+// competition, their matches are dropped, so a "//" comment rule wins against a "/" operator rule.
+package lexer
+
+import (
+	"errors"
+
+	"github.com/jojomi/textparser"
+)
+
+// Kind identifies the type of a token, e.g. "ident" or "number".
+type Kind string
+
+// EOF is the kind of the token returned at the end of input.
+const EOF Kind = "EOF"
+
+// Token is a piece of input matched by a rule. Its Span can be converted to line and column information using
+// textparser.Parser.SpanPositions.
+type Token struct {
+	Kind Kind
+	Text string
+	Span textparser.Span
+}
+
+type rule struct {
+	kind     Kind
+	priority int
+	skip     bool
+	match    Matcher
+}
+
+// Lexer produces tokens from the current position of a parser. The parser is only advanced by consumed tokens, so
+// it can be used directly between calls, e.g. to read a part of the input the rules don't cover.
+type Lexer struct {
+	parser *textparser.Parser
+	rules  []rule
+
+	// token found by the last Peek, valid as long as the parser is still at peekedIndex
+	peeked      *Token
+	peekedIndex int
+}
+
+// New creates a lexer reading from p. Add rules using Rule, RuleWithPriority and Skip.
+func New(p *textparser.Parser) *Lexer {
+	return &Lexer{
+		parser: p,
+	}
+}
+
+// Rule adds a rule producing tokens of kind (chainable).
+func (x *Lexer) Rule(kind Kind, m Matcher) *Lexer {
+	return x.RuleWithPriority(kind, 0, m)
+}
+
+// RuleWithPriority adds a rule producing tokens of kind (chainable). If several rules match the same length, the
+// one with the highest priority wins, e.g. a keyword against an identifier.
+func (x *Lexer) RuleWithPriority(kind Kind, priority int, m Matcher) *Lexer {
+	x.rules = append(x.rules, rule{kind: kind, priority: priority, match: m})
+	return x
+}
+
+// Skip adds a rule for input that is dropped between tokens, like whitespace or comments (chainable).
+func (x *Lexer) Skip(m Matcher) *Lexer {
+	x.rules = append(x.rules, rule{skip: true, match: m})
+	return x
+}
+
+// Parser returns the parser the lexer reads from.
+func (x *Lexer) Parser() *textparser.Parser {
+	return x.parser
+}
+
+// Peek returns the next token without consuming it. At the end of input, a token of kind EOF is returned.
+func (x *Lexer) Peek() (Token, error) {
+	if x.peeked != nil && x.peekedIndex == x.parser.CurrentIndex() {
+		return *x.peeked, nil
+	}
+
+	mark := x.parser.Mark()
+	token, err := x.scan()
+	x.parser.Reset(mark)
+	if err != nil {
+		return Token{}, err
+	}
+
+	x.peeked = &token
+	x.peekedIndex = mark.Index()
+	return token, nil
+}
+
+func (x *Lexer) MustPeek() Token {
+	token, err := x.Peek()
+	if err != nil {
+		panic(err)
+	}
+	return token
+}
+
+// Next consumes and returns the next token. At the end of input, a token of kind EOF is returned.
+func (x *Lexer) Next() (Token, error) {
+	token, err := x.Peek()
+	if err != nil {
+		return Token{}, err
+	}
+	x.parser.MustSkip(token.Span.End - x.parser.CurrentIndex())
+	return token, nil
+}
+
+func (x *Lexer) MustNext() Token {
+	token, err := x.Next()
+	if err != nil {
+		panic(err)
+	}
+	return token
+}
+
+// Expect consumes the next token if it is of kind. Otherwise, nothing is consumed and a *textparser.ParseError is
+// returned.
+func (x *Lexer) Expect(kind Kind) (Token, error) {
+	token, err := x.Peek()
+	if err != nil {
+		return Token{}, err
+	}
+	if token.Kind != kind {
+		return Token{}, x.unexpectedToken(string(kind), token)
+	}
+	return x.Next()
+}
+
+func (x *Lexer) MustExpect(kind Kind) Token {
+	token, err := x.Expect(kind)
+	if err != nil {
+		panic(err)
+	}
+	return token
+}
+
+// All consumes the remaining tokens, excluding the final EOF token.
+func (x *Lexer) All() ([]Token, error) {
+	var tokens []Token
+	for {
+		token, err := x.Next()
+		if err != nil {
+			return tokens, err
+		}
+		if token.Kind == EOF {
+			return tokens, nil
+		}
+		tokens = append(tokens, token)
+	}
+}
+
+// scan advances the parser past skipped input and the next token.
+func (x *Lexer) scan() (Token, error) {
+	p := x.parser
+	for {
+		start := p.CurrentIndex()
+		if p.IsExhausted() {
+			return x.token(EOF, start, start), nil
+		}
+		if err := p.Step(); err != nil {
+			return Token{}, err
+		}
+
+		best, bestEnd := -1, start
+		for i, r := range x.rules {
+			end, err := x.matchLength(r.match)
+			if err != nil {
+				return Token{}, err
+			}
+			if end > bestEnd || (end == bestEnd && end > start && x.rules[best].priority < r.priority) {
+				best, bestEnd = i, end
+			}
+		}
+		if best < 0 {
+			return Token{}, p.Unexpected("token", 10)
+		}
+
+		if x.rules[best].skip {
+			p.MustSkip(bestEnd - start)
+			continue
+		}
+		if err := p.CheckTokenLength(bestEnd - start); err != nil {
+			return Token{}, err
+		}
+		p.MustSkip(bestEnd - start)
+		return x.token(x.rules[best].kind, start, bestEnd), nil
+	}
+}
+
+// matchLength runs m at the current position and returns the index it reached, leaving the parser unchanged. If m
+// fails, the current index is returned. Only errors from exceeded limits are passed on, as they end lexing.
+func (x *Lexer) matchLength(m Matcher) (int, error) {
+	mark := x.parser.Mark()
+	defer x.parser.Reset(mark)
+
+	err := m(x.parser)
+	var limitErr *textparser.LimitError
+	if errors.As(err, &limitErr) {
+		return mark.Index(), err
+	}
+	if err != nil {
+		return mark.Index(), nil
+	}
+	return x.parser.CurrentIndex(), nil
+}
+
+func (x *Lexer) token(kind Kind, start, end int) Token {
+	p := x.parser
+	text := ""
+	if end > start {
+		text = p.MustExtract(start, end)
+	}
+	return Token{
+		Kind: kind,
+		Text: text,
+		Span: textparser.Span{Start: start, End: end},
+	}
+}
+
+// unexpectedToken reports that expected was wanted, but token was found.
+func (x *Lexer) unexpectedToken(expected string, token Token) *textparser.ParseError {
+	if token.Kind == EOF {
+		return x.parser.NewParseErrorAt(token.Span.Start, textparser.EndOfInput, expected, "", nil)
+	}
+	return x.parser.NewParseErrorAt(token.Span.Start, textparser.UnexpectedInput, expected, token.Text, nil)
+}
@@ -0,0 +1,132 @@
+package lexer
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/jojomi/textparser"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	keyword Kind = "keyword"
+	ident   Kind = "ident"
+	number  Kind = "number"
+	op      Kind = "op"
+)
+
+func newTestLexer(input string) *Lexer {
+	return New(textparser.NewParser(input)).
+		Skip(Set(textparser.MustCompileRuneSet(`\s`))).
+		Skip(Regexp(regexp.MustCompile(`//[^\n]*`))).
+		RuleWithPriority(keyword, 1, Literal("if")).
+		Rule(ident, Set(textparser.MustCompileRuneSet(`[a-zA-Z_]`))).
+		Rule(number, Func((*textparser.Parser).ReadInt)).
+		Rule(op, Literal("/")).
+		Rule(op, Literal("=")).
+		Rule(op, Literal("=="))
+}
+
+func TestLexer_All(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "empty",
+			input: "  // nothing\n",
+			want:  nil,
+		},
+		{
+			name:  "keyword has priority at same length",
+			input: "if iffy",
+			want:  []string{"keyword:if", "ident:iffy"},
+		},
+		{
+			name:  "longest match",
+			input: "a == 12 / b = 3 // comment",
+			want:  []string{"ident:a", "op:==", "number:12", "op:/", "ident:b", "op:=", "number:3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := newTestLexer(tt.input).All()
+			assert.Nil(t, err)
+
+			var got []string
+			for _, token := range tokens {
+				got = append(got, string(token.Kind)+":"+token.Text)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLexer_PeekNextExpect(t *testing.T) {
+	a := assert.New(t)
+
+	l := newTestLexer("if\n  x == 4")
+	a.Equal(keyword, l.MustPeek().Kind)
+	a.Equal(0, l.Parser().CurrentIndex())
+
+	a.Equal(Token{
+		Kind: keyword,
+		Text: "if",
+		Span: textparser.Span{Start: 0, End: 2},
+	}, l.MustNext())
+
+	_, err := l.Expect(number)
+	var parseErr *textparser.ParseError
+	a.True(errors.As(err, &parseErr))
+	a.Equal(textparser.UnexpectedInput, parseErr.Kind)
+	a.Equal("x", parseErr.Found)
+	a.Equal(2, parseErr.Position.Line)
+	a.Equal(3, parseErr.Position.Column)
+
+	token := l.MustExpect(ident)
+	a.Equal("x", token.Text)
+	start, _ := l.Parser().SpanPositions(token.Span)
+	a.Equal(textparser.Position{Offset: 5, Line: 2, Column: 3, ByteOffset: 5}, start)
+
+	// the parser can be used between tokens
+	l.Parser().MustSkip(3)
+	a.Equal("4", l.MustNext().Text)
+
+	a.Equal(EOF, l.MustNext().Kind)
+	_, err = l.Expect(ident)
+	a.ErrorIs(err, textparser.EndOfInputError{})
+}
+
+func TestLexer_noMatch(t *testing.T) {
+	a := assert.New(t)
+
+	l := newTestLexer("a ?b")
+	a.Equal("a", l.MustNext().Text)
+	_, err := l.Next()
+	a.EqualError(err, `unexpected input at line 1, column 3, expected token, found "?b"`)
+}
+
+func TestLexer_limits(t *testing.T) {
+	a := assert.New(t)
+	var limitErr *textparser.LimitError
+
+	// the error of a matcher is not dropped in favor of another rule
+	p := textparser.NewParser("123456789").SetLimits(textparser.Limits{MaxTokenLength: 4})
+	l := New(p).
+		Rule("digits", Set(textparser.MustCompileRuneSet(`[0-9]`))).
+		Rule(number, Func((*textparser.Parser).ReadInt))
+	_, err := l.Next()
+	a.True(errors.As(err, &limitErr))
+	a.Equal("token length", limitErr.Limit)
+	a.Equal(0, p.CurrentIndex())
+
+	// tokens of matchers without a limit check of their own are checked, too
+	l = newTestLexer("abc abcde")
+	l.Parser().SetLimits(textparser.Limits{MaxTokenLength: 4})
+	a.Equal("abc", l.MustNext().Text)
+	_, err = l.Next()
+	a.True(errors.As(err, &limitErr))
+	a.Equal(3, l.Parser().CurrentIndex())
+}
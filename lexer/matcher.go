@@ -0,0 +1,42 @@
+package lexer
+
+import (
+	"regexp"
+
+	"github.com/jojomi/textparser"
+)
+
+// Matcher matches a token at the current position of p by advancing past it. It returns an error if there is no
+// match. Matchers don't need to reset the parser on failure, and matches of length zero are ignored.
+type Matcher func(p *textparser.Parser) error
+
+// Literal matches the string s.
+func Literal(s string) Matcher {
+	return func(p *textparser.Parser) error {
+		return p.SkipString(s)
+	}
+}
+
+// Set matches one or more runes contained in set.
+func Set(set *textparser.RuneSet) Matcher {
+	return func(p *textparser.Parser) error {
+		p.SkipSet(set)
+		return nil
+	}
+}
+
+// Regexp matches re at the current position, see textparser.Parser.ReadRegexp.
+func Regexp(re *regexp.Regexp) Matcher {
+	return func(p *textparser.Parser) error {
+		_, err := p.ReadRegexp(re)
+		return err
+	}
+}
+
+// Func matches using f, which reads the token from p, e.g. (*textparser.Parser).ReadInt.
+func Func[T any](f func(p *textparser.Parser) (T, error)) Matcher {
+	return func(p *textparser.Parser) error {
+		_, err := f(p)
+		return err
+	}
+}
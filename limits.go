@@ -0,0 +1,107 @@
+package textparser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Limits guard against runaway parsing on hostile input. Zero values mean no limit.
+type Limits struct {
+	// MaxDepth limits the nesting depth of delimiters in ReadToMatching…, ReadBalanced and friends, plus the nesting
+	// tracked by EnterNesting, e.g. by the expr, peg and combinator packages.
+	MaxDepth int
+	// MaxTokenLength limits the number of runes a single read returning an error may return, and the length of the
+	// tokens of the lexer package.
+	MaxTokenLength int
+	// MaxSteps limits the total number of steps counted by Step, e.g. for every rule run by the expr, peg, lexer and
+	// combinator packages, and by the delimiter matching functions. Steps add up over the lifetime of the parser,
+	// including repeated parses of the same input, until SetLimits or ResetSteps start counting anew.
+	MaxSteps int
+}
+
+// LimitError is wrapped by the ParseError returned when a limit is exceeded.
+type LimitError struct {
+	// Limit is the name of the limit: "depth", "token length" or "steps".
+	Limit string
+	Max   int
+}
+
+func (x *LimitError) Error() string {
+	return fmt.Sprintf("%s limit of %d exceeded", x.Limit, x.Max)
+}
+
+// SetLimits sets the limits of the parser and resets the steps counted so far (chainable).
+func (x *Parser) SetLimits(limits Limits) *Parser {
+	x.limits = limits
+	x.steps = 0
+	return x
+}
+
+// ResetSteps resets the steps counted so far towards MaxSteps (chainable), e.g. before parsing the input again.
+func (x *Parser) ResetSteps() *Parser {
+	x.steps = 0
+	return x
+}
+
+// Limits returns the limits of the parser.
+func (x *Parser) Limits() Limits {
+	return x.limits
+}
+
+// EnterNesting counts a level of nesting, which must be left using ExitNesting. It fails if MaxDepth is exceeded.
+// Recursive parsers built on the parser should call it to turn deeply nested input into an error instead of a stack
+// overflow.
+func (x *Parser) EnterNesting() error {
+	err := x.checkDepth(1)
+	if err != nil {
+		return err
+	}
+	x.depth++
+	return nil
+}
+
+// ExitNesting leaves a level of nesting entered by EnterNesting.
+func (x *Parser) ExitNesting() {
+	x.depth = max(0, x.depth-1)
+}
+
+// Step counts a step of work. It fails if MaxSteps is exceeded.
+func (x *Parser) Step() error {
+	x.steps++
+	if x.limits.MaxSteps > 0 && x.steps > x.limits.MaxSteps {
+		return x.limitError("steps", x.limits.MaxSteps)
+	}
+	return nil
+}
+
+// CheckTokenLength fails if a token of runeCount runes exceeds MaxTokenLength. Parsers built on the parser should call it
+// for tokens they read without the reading functions of Parser, which check it themselves.
+func (x *Parser) CheckTokenLength(runeCount int) error {
+	return x.checkTokenLength(runeCount)
+}
+
+// isLimitError determines if err was caused by an exceeded limit.
+func isLimitError(err error) bool {
+	var limitErr *LimitError
+	return errors.As(err, &limitErr)
+}
+
+// checkDepth fails if depth levels of nesting on top of the ones entered by EnterNesting exceed MaxDepth.
+func (x *Parser) checkDepth(depth int) error {
+	if x.limits.MaxDepth > 0 && x.depth+depth > x.limits.MaxDepth {
+		return x.limitError("depth", x.limits.MaxDepth)
+	}
+	return nil
+}
+
+// checkTokenLength fails if a token of runeCount runes exceeds MaxTokenLength.
+func (x *Parser) checkTokenLength(runeCount int) error {
+	if x.limits.MaxTokenLength > 0 && runeCount > x.limits.MaxTokenLength {
+		return x.limitError("token length", x.limits.MaxTokenLength)
+	}
+	return nil
+}
+
+func (x *Parser) limitError(limit string, maxValue int) *ParseError {
+	return x.NewParseError(LimitExceeded, "", "", &LimitError{Limit: limit, Max: maxValue})
+}
@@ -0,0 +1,151 @@
+package textparser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParser_SetLimits(t *testing.T) {
+	deep := strings.Repeat("(", 10) + strings.Repeat(")", 10)
+
+	tests := []struct {
+		name      string
+		input     string
+		limits    Limits
+		read      func(p *Parser) (string, error)
+		wantLimit string
+	}{
+		{
+			name:   "depth, matching rune",
+			input:  deep,
+			limits: Limits{MaxDepth: 5},
+			read: func(p *Parser) (string, error) {
+				return p.ReadToMatchingRuneSkipDelims('(', ')')
+			},
+			wantLimit: "depth",
+		},
+		{
+			name:   "depth, matching string",
+			input:  deep,
+			limits: Limits{MaxDepth: 5},
+			read: func(p *Parser) (string, error) {
+				return p.ReadToMatchingStringSkipDelims("(", ")")
+			},
+			wantLimit: "depth",
+		},
+		{
+			name:   "depth, balanced",
+			input:  deep,
+			limits: Limits{MaxDepth: 5},
+			read: func(p *Parser) (string, error) {
+				return p.ReadBalanced([]BracketPair{{'(', ')'}})
+			},
+			wantLimit: "depth",
+		},
+		{
+			name:   "depth within limit",
+			input:  deep,
+			limits: Limits{MaxDepth: 10},
+			read: func(p *Parser) (string, error) {
+				return p.ReadBalanced([]BracketPair{{'(', ')'}})
+			},
+		},
+		{
+			name:   "token length, word",
+			input:  "abcdefgh ij",
+			limits: Limits{MaxTokenLength: 4},
+			read: func(p *Parser) (string, error) {
+				return p.ReadWord()
+			},
+			wantLimit: "token length",
+		},
+		{
+			name:   "token length, rest of input",
+			input:  "abcdefgh ij",
+			limits: Limits{MaxTokenLength: 4},
+			read: func(p *Parser) (string, error) {
+				return p.ReadRestOfInput()
+			},
+			wantLimit: "token length",
+		},
+		{
+			name:   "token length, matching",
+			input:  "(abcdefgh)",
+			limits: Limits{MaxTokenLength: 4},
+			read: func(p *Parser) (string, error) {
+				return p.ReadToMatchingWithOptionsSkipDelims("(", ")", MatchingOptions{})
+			},
+			wantLimit: "token length",
+		},
+		{
+			name:   "token length within limit",
+			input:  "abcd efgh",
+			limits: Limits{MaxTokenLength: 4},
+			read: func(p *Parser) (string, error) {
+				return p.ReadWord()
+			},
+		},
+		{
+			name:   "steps",
+			input:  "(" + strings.Repeat("a", 100) + ")",
+			limits: Limits{MaxSteps: 50},
+			read: func(p *Parser) (string, error) {
+				return p.ReadToMatchingRuneSkipDelims('(', ')')
+			},
+			wantLimit: "steps",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+
+			p := NewParser(tt.input).SetLimits(tt.limits)
+			a.Equal(tt.limits, p.Limits())
+			_, err := tt.read(p)
+			if tt.wantLimit == "" {
+				a.NoError(err)
+				return
+			}
+
+			var parseErr *ParseError
+			a.True(errors.As(err, &parseErr))
+			a.Equal(LimitExceeded, parseErr.Kind)
+			var limitErr *LimitError
+			a.True(errors.As(err, &limitErr))
+			a.Equal(tt.wantLimit, limitErr.Limit)
+		})
+	}
+}
+
+func TestParser_EnterNesting(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("(a)").SetLimits(Limits{MaxDepth: 2})
+	a.NoError(p.EnterNesting())
+	a.NoError(p.EnterNesting())
+	a.ErrorContains(p.EnterNesting(), "depth limit of 2 exceeded")
+
+	// nesting counts towards the depth of delimiters
+	_, err := p.ReadBalanced([]BracketPair{{'(', ')'}})
+	a.ErrorContains(err, "depth limit of 2 exceeded")
+
+	p.ExitNesting()
+	a.Equal("(a)", p.MustReadBalanced([]BracketPair{{'(', ')'}}))
+}
+
+func TestParser_Step(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("").SetLimits(Limits{MaxSteps: 2})
+	a.NoError(p.Step())
+	a.NoError(p.Step())
+	a.ErrorContains(p.Step(), "steps limit of 2 exceeded")
+
+	p.ResetSteps()
+	a.NoError(p.Step())
+	a.NoError(p.SetLimits(Limits{MaxSteps: 1}).Step())
+	a.ErrorContains(p.Step(), "steps limit of 1 exceeded")
+}
@@ -0,0 +1,200 @@
+package textparser
+
+import (
+	"errors"
+)
+
+// errLeftRecursion is the result of a left-recursive call before the recursion has been resolved.
+var errLeftRecursion = errors.New("left recursion")
+
+// MemoStats are the statistics of the memo table, see Parser.EnableMemo.
+type MemoStats struct {
+	// Hits counts the calls of Memoize answered from the table.
+	Hits int
+	// Misses counts the calls of Memoize that ran the rule.
+	Misses int
+	// Entries is the number of results in the table.
+	Entries int
+}
+
+// memoTable caches rule results by position and rule.
+type memoTable struct {
+	entries map[int]map[any]*memoEntry
+	// rule whose left recursion is being grown at a position
+	growing map[int]any
+	// counts entries, to tell which ones were created while a rule was being evaluated
+	sequence int
+	stats    MemoStats
+}
+
+type memoEntry struct {
+	value any
+	err   error
+	end   int
+	// creation order, see memoTable.sequence
+	sequence int
+	// set while the rule is being evaluated for the first time
+	inProgress bool
+	// set if the rule called itself at the same position while in progress
+	leftRecursive bool
+}
+
+// EnableMemo turns on memoization for Memoize (chainable). It makes backtracking parsers run in linear time at the
+// cost of memory, and allows left-recursive rules.
+func (x *Parser) EnableMemo() *Parser {
+	if x.memo == nil {
+		x.memo = &memoTable{
+			entries: make(map[int]map[any]*memoEntry),
+			growing: make(map[int]any),
+		}
+	}
+	return x
+}
+
+// ClearMemo drops all results from the memo table (chainable), e.g. before parsing the input again with a grammar
+// that collects more than the results from its rules. The statistics are kept, except for the number of entries.
+func (x *Parser) ClearMemo() *Parser {
+	if x.memo != nil {
+		clear(x.memo.entries)
+		clear(x.memo.growing)
+		x.memo.stats.Entries = 0
+	}
+	return x
+}
+
+// MemoStats returns the statistics of the memo table. They are zero if memoization is not enabled.
+func (x *Parser) MemoStats() MemoStats {
+	if x.memo == nil {
+		return MemoStats{}
+	}
+	return x.memo.stats
+}
+
+// Memoize runs the rule f at the current position, unless its result is already known from an earlier call with the
+// same rule at the same position. rule identifies the rule and must be comparable, e.g. a pointer. On failure, the
+// parser is reset to where it started, and the value f returned is passed on along with the error. Only the position
+// is restored from the table: rules should not depend on or change other parser state, like captures. Errors from
+// exceeded limits are not stored, so that the rule is run again after raising them.
+//
+// Memoize does not count steps or nesting, see Limits: rules that recurse should do so in f, so that reevaluations of
+// left-recursive rules are counted, too.
+//
+// Without EnableMemo, f is just run. With it, left recursion is supported: a rule calling itself at the same position
+// fails there at first, and the rule is then reevaluated as long as that makes it match more input, each time using
+// the previous result for the recursive call.
+func Memoize[T any](p *Parser, rule any, f func(p *Parser) (T, error)) (T, error) {
+	if p.memo == nil {
+		return runRule(p, f)
+	}
+
+	var (
+		value T
+		memo  = p.memo
+		start = p.position
+	)
+
+	// while growing a left-recursive rule, the other rules at its position depend on the growing result
+	if head, ok := memo.growing[start]; ok && head != rule {
+		memo.stats.Misses++
+		return runRule(p, f)
+	}
+
+	if entry := memo.entries[start][rule]; entry != nil {
+		memo.stats.Hits++
+		if entry.inProgress {
+			entry.leftRecursive = true
+		}
+		if entry.err != nil {
+			value, _ = entry.value.(T)
+			return value, entry.err
+		}
+		p.position = entry.end
+		return entry.value.(T), nil
+	}
+
+	memo.stats.Misses++
+	entry := &memoEntry{
+		err:        p.NewParseError(UnexpectedInput, "", "", errLeftRecursion),
+		end:        start,
+		sequence:   memo.sequence,
+		inProgress: true,
+	}
+	memo.store(start, rule, entry)
+
+	value, err := runRule(p, f)
+	entry.inProgress = false
+	entry.value, entry.err, entry.end = value, err, p.position
+	if isLimitError(err) {
+		memo.remove(start, rule)
+		return value, err
+	}
+	if !entry.leftRecursive {
+		return value, err
+	}
+
+	// grow the seed
+	var limitErr error
+	memo.growing[start] = rule
+	for {
+		p.position = start
+		grown, err := runRule(p, f)
+		if isLimitError(err) {
+			value, limitErr = grown, err
+			break
+		}
+		if err != nil || p.position <= entry.end {
+			break
+		}
+		entry.value, entry.err, entry.end = grown, nil, p.position
+	}
+	delete(memo.growing, start)
+
+	// results of other rules at this position were based on a seed
+	for other, e := range memo.entries[start] {
+		if other != rule && e.sequence > entry.sequence {
+			delete(memo.entries[start], other)
+			memo.stats.Entries--
+		}
+	}
+
+	if limitErr != nil {
+		memo.remove(start, rule)
+		p.position = start
+		return value, limitErr
+	}
+	if entry.err != nil {
+		p.position = start
+		return value, entry.err
+	}
+	p.position = entry.end
+	return entry.value.(T), nil
+}
+
+// runRule runs f, resetting the parser on failure.
+func runRule[T any](p *Parser, f func(p *Parser) (T, error)) (T, error) {
+	var value T
+	err := p.Attempt(func(p *Parser) error {
+		var err error
+		value, err = f(p)
+		return err
+	})
+	return value, err
+}
+
+func (x *memoTable) store(position int, rule any, entry *memoEntry) {
+	rules := x.entries[position]
+	if rules == nil {
+		rules = make(map[any]*memoEntry)
+		x.entries[position] = rules
+	}
+	rules[rule] = entry
+	x.sequence++
+	x.stats.Entries++
+}
+
+func (x *memoTable) remove(position int, rule any) {
+	if _, ok := x.entries[position][rule]; ok {
+		delete(x.entries[position], rule)
+		x.stats.Entries--
+	}
+}
@@ -0,0 +1,156 @@
+package textparser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoGrammar is a left-recursive grammar for subtractions:
+//
+//	diff <- diff '-' num / num    (direct left recursion)
+//	term <- sub / num             (indirect left recursion through sub)
+//	sub  <- term '-' num
+type memoGrammar struct {
+	calls int
+}
+
+func (x *memoGrammar) diff(p *Parser) (int, error) {
+	return Memoize(p, "diff", func(p *Parser) (int, error) {
+		x.calls++
+		start := p.Mark()
+		left, err := x.diff(p)
+		if err == nil && p.SkipString("-") == nil {
+			right, err := p.ReadInt()
+			if err == nil {
+				return left - right, nil
+			}
+		}
+		p.Reset(start)
+		return p.ReadInt()
+	})
+}
+
+func (x *memoGrammar) term(p *Parser) (int, error) {
+	return Memoize(p, "term", func(p *Parser) (int, error) {
+		start := p.Mark()
+		value, err := x.sub(p)
+		if err == nil {
+			return value, nil
+		}
+		p.Reset(start)
+		return p.ReadInt()
+	})
+}
+
+func (x *memoGrammar) sub(p *Parser) (int, error) {
+	return Memoize(p, "sub", func(p *Parser) (int, error) {
+		left, err := x.term(p)
+		if err != nil {
+			return 0, err
+		}
+		err = p.SkipString("-")
+		if err != nil {
+			return 0, err
+		}
+		right, err := p.ReadInt()
+		return left - right, err
+	})
+}
+
+func TestMemoize_leftRecursion(t *testing.T) {
+	a := assert.New(t)
+
+	g := &memoGrammar{}
+	p := NewParser("10-2-3;").EnableMemo()
+	value, err := g.diff(p)
+	a.Nil(err)
+	a.Equal(5, value)
+	a.Equal(";", p.Remaining())
+
+	// answered from the table
+	calls := g.calls
+	p.Reset(Mark{})
+	value, err = g.diff(p)
+	a.Nil(err)
+	a.Equal(5, value)
+	a.Equal(calls, g.calls)
+	a.Equal(6, p.CurrentIndex())
+
+	p = NewParser("10-2-3").EnableMemo()
+	value, err = g.term(p)
+	a.Nil(err)
+	a.Equal(5, value)
+	a.True(p.IsExhausted())
+}
+
+func TestMemoize_stats(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(MemoStats{}, NewParser("").MemoStats())
+
+	p := NewParser("12 x").EnableMemo()
+	readTwice := func(rule string) {
+		for i := 0; i < 2; i++ {
+			p.Reset(Mark{})
+			_, _ = Memoize(p, rule, (*Parser).ReadInt)
+		}
+	}
+	readTwice("int")
+	a.Equal(2, p.CurrentIndex())
+	a.Equal(MemoStats{Hits: 1, Misses: 1, Entries: 1}, p.MemoStats())
+
+	// failures are cached, too, and leave the parser where it was
+	p.MustSkip(1)
+	for i := 0; i < 2; i++ {
+		_, err := Memoize(p, "int", (*Parser).ReadInt)
+		a.NotNil(err)
+		a.Equal(3, p.CurrentIndex())
+	}
+	a.Equal(MemoStats{Hits: 2, Misses: 2, Entries: 2}, p.MemoStats())
+}
+
+func TestMemoize_limits(t *testing.T) {
+	a := assert.New(t)
+
+	nesting := func(f func(p *Parser) (int, error)) func(p *Parser) (int, error) {
+		return func(p *Parser) (int, error) {
+			if err := p.EnterNesting(); err != nil {
+				return 0, err
+			}
+			defer p.ExitNesting()
+			return f(p)
+		}
+	}
+	nested := nesting(func(p *Parser) (int, error) {
+		return Memoize(p, "int", nesting((*Parser).ReadInt))
+	})
+
+	p := NewParser("12").EnableMemo().SetLimits(Limits{MaxDepth: 1})
+	_, err := Memoize(p, "nested", nested)
+	var limitErr *LimitError
+	a.True(errors.As(err, &limitErr))
+	a.Equal(0, p.CurrentIndex())
+	a.Equal(0, p.MemoStats().Entries)
+
+	p.SetLimits(Limits{MaxDepth: 2})
+	value, err := Memoize(p, "nested", nested)
+	a.Nil(err)
+	a.Equal(12, value)
+}
+
+func TestMemoize_disabled(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("12 x")
+	value, err := Memoize(p, "int", (*Parser).ReadInt)
+	a.Nil(err)
+	a.Equal(12, value)
+
+	p.MustSkip(1)
+	_, err = Memoize(p, "int", (*Parser).ReadInt)
+	a.NotNil(err)
+	a.Equal(3, p.CurrentIndex())
+	a.Equal(MemoStats{}, p.MemoStats())
+}
@@ -0,0 +1,190 @@
+package textparser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrorKind classifies a ParseError.
+type ErrorKind int
+
+const (
+	// UnexpectedInput means the input at the position did not match what was expected.
+	UnexpectedInput ErrorKind = iota + 1
+	// EndOfInput means the input ended before the expected content was found.
+	EndOfInput
+	// Unbalanced means opening and closing delimiters did not match up.
+	Unbalanced
+	// NumberOverflow means a number was found, but it does not fit the requested type.
+	NumberOverflow
+	// InvalidArgument means the parser was called with arguments that can't be satisfied, e.g. a negative index.
+	InvalidArgument
+	// LimitExceeded means one of the limits set by Parser.SetLimits was hit. The error wraps a *LimitError.
+	LimitExceeded
+)
+
+func (x ErrorKind) String() string {
+	switch x {
+	case UnexpectedInput:
+		return "unexpected input"
+	case EndOfInput:
+		return "unexpected end of input"
+	case Unbalanced:
+		return "unbalanced delimiters"
+	case NumberOverflow:
+		return "number overflow"
+	case InvalidArgument:
+		return "invalid argument"
+	case LimitExceeded:
+		return "limit exceeded"
+	}
+	return fmt.Sprintf("ErrorKind(%d)", int(x))
+}
+
+// ParseError is returned by all reading and skipping functions of Parser. Use errors.As to inspect it.
+// Errors of kind EndOfInput wrap EndOfInputError, so errors.Is(err, EndOfInputError{}) keeps working.
+type ParseError struct {
+	Kind ErrorKind
+	// Expected describes what the parser was looking for, e.g. `"Game "` or `integer`.
+	Expected string
+	// Found is the input found instead, if any.
+	Found    string
+	Position Position
+	// Err is the underlying cause, if any.
+	Err error
+}
+
+// PositionedError is implemented by errors that know where in the input they occurred, like ParseError and the
+// errors of the combinator and peg packages. RenderError uses it to point at the offending input.
+type PositionedError interface {
+	error
+	// ErrorPosition returns where the error occurred.
+	ErrorPosition() Position
+	// ErrorFound returns the input found at the position, which may be empty.
+	ErrorFound() string
+	// ErrorMessage returns the message of the error without the position.
+	ErrorMessage() string
+}
+
+func (x *ParseError) Error() string {
+	return x.message(true)
+}
+
+func (x *ParseError) ErrorPosition() Position {
+	return x.Position
+}
+
+func (x *ParseError) ErrorFound() string {
+	return x.Found
+}
+
+func (x *ParseError) ErrorMessage() string {
+	return x.message(false)
+}
+
+// message formats the error, optionally including its position.
+func (x *ParseError) message(withPosition bool) string {
+	var b strings.Builder
+	b.WriteString(x.Kind.String())
+	if withPosition {
+		b.WriteString(" at ")
+		b.WriteString(x.Position.String())
+	}
+	if x.Expected != "" {
+		b.WriteString(", expected ")
+		b.WriteString(x.Expected)
+	}
+	if x.Found != "" {
+		fmt.Fprintf(&b, ", found %q", x.Found)
+	}
+	if x.Err != nil && x.Err != (EndOfInputError{}) {
+		b.WriteString(": ")
+		b.WriteString(x.Err.Error())
+	}
+	return b.String()
+}
+
+func (x *ParseError) Unwrap() error {
+	return x.Err
+}
+
+// NewParseError creates a ParseError at the current position. Packages building on the parser use it to report their
+// own failures in the same way. For errors of kind EndOfInput, a nil cause is replaced by EndOfInputError.
+func (x *Parser) NewParseError(kind ErrorKind, expected, found string, cause error) *ParseError {
+	return x.NewParseErrorAt(x.position, kind, expected, found, cause)
+}
+
+// NewParseErrorAt is like NewParseError, but creates the error at the rune index.
+func (x *Parser) NewParseErrorAt(index int, kind ErrorKind, expected, found string, cause error) *ParseError {
+	if kind == EndOfInput && cause == nil {
+		cause = EndOfInputError{}
+	}
+	return &ParseError{
+		Kind:     kind,
+		Expected: expected,
+		Found:    found,
+		Position: x.PositionAt(index),
+		Err:      cause,
+	}
+}
+
+// Unexpected reports that expected is not at the current position, showing foundLength runes of what is. At the end of
+// input, the error is of kind EndOfInput.
+func (x *Parser) Unexpected(expected string, foundLength int) *ParseError {
+	if x.IsExhausted() {
+		return x.NewParseError(EndOfInput, expected, "", nil)
+	}
+	return x.NewParseError(UnexpectedInput, expected, x.GetNextMax(foundLength), nil)
+}
+
+// FormatFailure formats a failure to match any of expected at position, where found is the input there. It is used by
+// parsers collecting several alternatives, like the combinator and peg packages. context is added after the position,
+// e.g. "in rule Value", if it is not empty.
+func FormatFailure(position Position, context string, expected []string, found string) string {
+	return formatFailure(&position, context, expected, found)
+}
+
+// FormatFailureMessage is like FormatFailure, but leaves out the position, see PositionedError.
+func FormatFailureMessage(context string, expected []string, found string) string {
+	return formatFailure(nil, context, expected, found)
+}
+
+func formatFailure(position *Position, context string, expected []string, found string) string {
+	var b strings.Builder
+	if found == "" {
+		b.WriteString(EndOfInput.String())
+	} else {
+		b.WriteString(UnexpectedInput.String())
+	}
+	if position != nil {
+		b.WriteString(" at ")
+		b.WriteString(position.String())
+	}
+	if context != "" {
+		b.WriteString(" ")
+		b.WriteString(context)
+	}
+	switch len(expected) {
+	case 0:
+	case 1:
+		b.WriteString(", expected ")
+		b.WriteString(expected[0])
+	default:
+		b.WriteString(", expected one of ")
+		b.WriteString(strings.Join(expected, ", "))
+	}
+	if found != "" {
+		fmt.Fprintf(&b, ", found %q", found)
+	}
+	return b.String()
+}
+
+// quoteAll formats values as a human-readable list of quoted strings.
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return strings.Join(quoted, ", ")
+}
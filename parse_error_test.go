@@ -0,0 +1,127 @@
+package textparser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		parse        func(p *Parser) error
+		wantKind     ErrorKind
+		wantExpected string
+		wantFound    string
+		wantOffset   int
+		wantEnd      bool
+	}{
+		{
+			name:  "skip string",
+			input: "role: CEO",
+			parse: func(p *Parser) error {
+				return p.MustSkipString("role: ").SkipString("CTO")
+			},
+			wantKind:     UnexpectedInput,
+			wantExpected: `"CTO"`,
+			wantFound:    "CEO",
+			wantOffset:   6,
+		},
+		{
+			name:  "skip string at end",
+			input: "role",
+			parse: func(p *Parser) error {
+				return p.MustSkipString("role").SkipString(":")
+			},
+			wantKind:     EndOfInput,
+			wantExpected: `":"`,
+			wantOffset:   4,
+			wantEnd:      true,
+		},
+		{
+			name:  "read int",
+			input: "Game x",
+			parse: func(p *Parser) error {
+				_, err := p.MustSkipString("Game ").ReadInt()
+				return err
+			},
+			wantKind:     UnexpectedInput,
+			wantExpected: "integer",
+			wantFound:    "x",
+			wantOffset:   5,
+		},
+		{
+			name:  "read int overflow",
+			input: "99999999999999999999",
+			parse: func(p *Parser) error {
+				_, err := p.ReadInt()
+				return err
+			},
+			wantKind:     NumberOverflow,
+			wantExpected: "integer",
+			wantFound:    "99999999999999999999",
+		},
+		{
+			name:  "read runes",
+			input: "ab",
+			parse: func(p *Parser) error {
+				_, err := p.ReadRunes(3)
+				return err
+			},
+			wantKind:     EndOfInput,
+			wantExpected: "3 runes",
+			wantEnd:      true,
+		},
+		{
+			name:  "matching rune exhausted",
+			input: "(ab",
+			parse: func(p *Parser) error {
+				_, err := p.MustSkip(1).ReadToMatchingRune('(', ')')
+				return err
+			},
+			wantKind:     EndOfInput,
+			wantExpected: "closing ')'",
+			wantOffset:   3,
+			wantEnd:      true,
+		},
+		{
+			name:  "matching string missing opener",
+			input: "ab))",
+			parse: func(p *Parser) error {
+				_, err := p.ReadToMatchingStringSkipDelims("((", "))")
+				return err
+			},
+			wantKind:     UnexpectedInput,
+			wantExpected: `opening "(("`,
+			wantFound:    "ab",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.parse(NewParser(tt.input))
+
+			var parseErr *ParseError
+			if !assert.True(t, errors.As(err, &parseErr), "not a ParseError: %v", err) {
+				return
+			}
+			assert.Equal(t, tt.wantKind, parseErr.Kind)
+			assert.Equal(t, tt.wantExpected, parseErr.Expected)
+			assert.Equal(t, tt.wantFound, parseErr.Found)
+			assert.Equal(t, tt.wantOffset, parseErr.Position.Offset)
+			assert.Equal(t, tt.wantEnd, errors.Is(err, EndOfInputError{}))
+		})
+	}
+}
+
+func TestParseError_Error(t *testing.T) {
+	a := assert.New(t)
+
+	err := NewParser("role: CEO").MustSkipString("role: ").SkipString("CTO")
+	a.EqualError(err, `unexpected input at line 1, column 7, expected "CTO", found "CEO"`)
+
+	err = NewParser("").SkipString("x")
+	a.EqualError(err, `unexpected end of input at line 1, column 1, expected "x"`)
+}
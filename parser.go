@@ -2,17 +2,45 @@ package textparser
 
 import (
 	"io"
+	"regexp"
 )
 
 type Parser struct {
-	input                []rune
+	// the original input, positions are rune indices into it, see input.go
+	input                string
 	position             int
 	captureStartPosition int
+	// named captures in the order they were begun, and the ones not ended yet
+	captures     []Capture
+	openCaptures *openCapture
+	// counts the calls of ClearCaptures, which invalidate the captures saved by Mark
+	captureGeneration int
+
+	// lazily computed by lineIndex
+	lineStarts []int
+
+	// lazily computed by indexInput
+	indexed      bool
+	runeCount    int
+	blockOffsets []int
+	// the last rune index converted by byteOffset per block
+	blockCursors []blockCursor
+
+	// nil unless enabled by EnableMemo
+	memo *memoTable
+
+	// lazily filled by anchoredRegexp
+	anchoredRegexps map[*regexp.Regexp]*regexp.Regexp
+
+	// see SetLimits
+	limits Limits
+	depth  int
+	steps  int
 }
 
 func NewParser(input string) *Parser {
 	return &Parser{
-		input:    []rune(input),
+		input:    input,
 		position: 0,
 	}
 }
@@ -23,7 +51,7 @@ func NewParserFromReader(input io.Reader) (*Parser, error) {
 	}
 
 	return &Parser{
-		input:    []rune(string(content)),
+		input:    string(content),
 		position: 0,
 	}, nil
 }
@@ -2,7 +2,8 @@ package textparser
 
 import "fmt"
 
-// StartCapture starts collecting output, see Captured to retrieve it.
+// StartCapture starts collecting output, see Captured to retrieve it. There is only one such capture at a time, use
+// BeginCapture for named and nested captures.
 func (x *Parser) StartCapture() *Parser {
 	x.captureStartPosition = x.CurrentIndex()
 	return x
@@ -13,6 +14,86 @@ func (x *Parser) Captured() string {
 	return x.MustExtract(x.captureStartPosition, x.CurrentIndex())
 }
 
+// Capture is a named part of the input, see BeginCapture.
+type Capture struct {
+	Name string
+	Text string
+	// Start and End are the rune indices of the capture.
+	Start int
+	End   int
+	// Depth is the number of captures enclosing this one.
+	Depth int
+}
+
+// openCapture is an element of the stack of captures begun but not ended yet. The stack is immutable, so a Mark can
+// keep a reference to it.
+type openCapture struct {
+	// index in Parser.captures
+	index  int
+	parent *openCapture
+}
+
+// BeginCapture starts a capture called name at the current position (chainable). Captures can be nested, each call
+// must be matched by a call of EndCapture.
+func (x *Parser) BeginCapture(name string) *Parser {
+	depth := 0
+	if x.openCaptures != nil {
+		depth = x.captures[x.openCaptures.index].Depth + 1
+	}
+	x.captures = append(x.captures, Capture{Name: name, Start: x.position, End: -1, Depth: depth})
+	x.openCaptures = &openCapture{index: len(x.captures) - 1, parent: x.openCaptures}
+	return x
+}
+
+// EndCapture ends the innermost capture begun by BeginCapture at the current position and returns it.
+func (x *Parser) EndCapture() (Capture, error) {
+	if x.openCaptures == nil {
+		return Capture{}, x.NewParseError(InvalidArgument, "", "", fmt.Errorf("no capture to end"))
+	}
+	capture := &x.captures[x.openCaptures.index]
+	capture.End = x.position
+	capture.Text = x.slice(capture.Start, capture.End)
+	x.openCaptures = x.openCaptures.parent
+	return *capture, nil
+}
+
+func (x *Parser) MustEndCapture() Capture {
+	capture, err := x.EndCapture()
+	if err != nil {
+		panic(err)
+	}
+	return capture
+}
+
+// Captures returns the ended captures in the order they were begun, so outer captures come before the ones nested
+// in them.
+func (x *Parser) Captures() []Capture {
+	result := make([]Capture, 0, len(x.captures))
+	for _, capture := range x.captures {
+		if capture.End >= 0 {
+			result = append(result, capture)
+		}
+	}
+	return result
+}
+
+// CapturesByName returns the ended captures grouped by name, each group in the order they were begun.
+func (x *Parser) CapturesByName() map[string][]Capture {
+	result := make(map[string][]Capture)
+	for _, capture := range x.Captures() {
+		result[capture.Name] = append(result[capture.Name], capture)
+	}
+	return result
+}
+
+// ClearCaptures drops all captures, including the ones not ended yet (chainable).
+func (x *Parser) ClearCaptures() *Parser {
+	x.captures = nil
+	x.openCaptures = nil
+	x.captureGeneration++
+	return x
+}
+
 func (x *Parser) MustExtract(start, end int) string {
 	v, err := x.Extract(start, end)
 	if err != nil {
@@ -29,8 +110,8 @@ func (x *Parser) Extract(start, end int) (string, error) {
 	if end <= start {
 		return "", fmt.Errorf("end index must be greater than start index, got start %d, end %d", start, end)
 	}
-	if end > len(x.input) {
-		return "", fmt.Errorf("end index must be less than input length, got %d, input length %d", end, len(x.input))
+	if end > x.length() {
+		return "", fmt.Errorf("end index must be less than input length, got %d, input length %d", end, x.length())
 	}
-	return string(x.input[start:end]), nil
+	return x.slice(start, end), nil
 }
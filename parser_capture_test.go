@@ -49,3 +49,76 @@ func TestParser_Extract(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_BeginCapture(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("name: Tom; role: CEO")
+	for p.HasMore() {
+		p.BeginCapture("record")
+		p.BeginCapture("key")
+		p.MustSkipToString(":")
+		a.Equal("key", p.MustEndCapture().Name)
+		p.MustSkip(2)
+		p.BeginCapture("value")
+		_ = p.MustReadToAnyStringOrEnd([]string{";"})
+		p.MustEndCapture()
+		record := p.MustEndCapture()
+		a.Equal("record", record.Name)
+		a.Equal(0, record.Depth)
+		if p.HasMore() {
+			p.MustSkip(2)
+		}
+	}
+
+	a.Equal([]Capture{
+		{Name: "record", Text: "name: Tom", Start: 0, End: 9, Depth: 0},
+		{Name: "key", Text: "name", Start: 0, End: 4, Depth: 1},
+		{Name: "value", Text: "Tom", Start: 6, End: 9, Depth: 1},
+		{Name: "record", Text: "role: CEO", Start: 11, End: 20, Depth: 0},
+		{Name: "key", Text: "role", Start: 11, End: 15, Depth: 1},
+		{Name: "value", Text: "CEO", Start: 17, End: 20, Depth: 1},
+	}, p.Captures())
+
+	byName := p.CapturesByName()
+	a.Len(byName["value"], 2)
+	a.Equal("CEO", byName["value"][1].Text)
+
+	_, err := p.EndCapture()
+	a.NotNil(err)
+	a.Empty(p.ClearCaptures().Captures())
+}
+
+func TestParser_CaptureReset(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("abc")
+	p.BeginCapture("outer")
+	p.MustSkip(1)
+	mark := p.Mark()
+
+	p.BeginCapture("inner").MustSkip(1)
+	p.MustEndCapture()
+	p.MustEndCapture()
+	a.Len(p.Captures(), 2)
+
+	// the inner capture is dropped, the outer one is open again
+	p.Reset(mark)
+	a.Empty(p.Captures())
+	p.MustSkip(2)
+	a.Equal(Capture{Name: "outer", Text: "abc", Start: 0, End: 3}, p.MustEndCapture())
+	a.Len(p.Captures(), 1)
+
+	// captures begun after clearing them are not mistaken for the ones of the mark
+	p = NewParser("abc")
+	p.BeginCapture("a")
+	mark = p.Mark()
+	p.MustEndCapture()
+	p.ClearCaptures().BeginCapture("x").MustSkip(1)
+	p.MustEndCapture()
+	p.Reset(mark)
+	a.Equal(0, p.CurrentIndex())
+	a.Equal([]Capture{{Name: "x", Text: "a", Start: 0, End: 1}}, p.Captures())
+	_, err := p.EndCapture()
+	a.Error(err)
+}
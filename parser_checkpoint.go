@@ -0,0 +1,55 @@
+package textparser
+
+// Mark is a checkpoint of the parser state. It is created by Parser.Mark and restored by Parser.Reset.
+type Mark struct {
+	position             int
+	captureStartPosition int
+	captureCount         int
+	openCaptures         *openCapture
+	captureGeneration    int
+}
+
+// Index returns the rune index the mark points to.
+func (x Mark) Index() int {
+	return x.position
+}
+
+// Mark saves the current state of the parser, so that it can be restored using Reset.
+func (x *Parser) Mark() Mark {
+	return Mark{
+		position:             x.position,
+		captureStartPosition: x.captureStartPosition,
+		captureCount:         len(x.captures),
+		openCaptures:         x.openCaptures,
+		captureGeneration:    x.captureGeneration,
+	}
+}
+
+// Reset restores the parser state saved by Mark (chainable).
+func (x *Parser) Reset(mark Mark) *Parser {
+	x.position = mark.position
+	x.captureStartPosition = mark.captureStartPosition
+
+	// drop captures begun later, and reopen the ones ended later, unless they were cleared in between
+	if mark.captureGeneration != x.captureGeneration || mark.captureCount > len(x.captures) {
+		return x
+	}
+	x.captures = x.captures[:mark.captureCount]
+	x.openCaptures = mark.openCaptures
+	for open := x.openCaptures; open != nil; open = open.parent {
+		x.captures[open.index].End = -1
+		x.captures[open.index].Text = ""
+	}
+	return x
+}
+
+// Attempt runs f and restores the parser state if it returns an error, so that f can consume input freely. The error
+// of f is returned unchanged.
+func (x *Parser) Attempt(f func(p *Parser) error) error {
+	mark := x.Mark()
+	err := f(x)
+	if err != nil {
+		x.Reset(mark)
+	}
+	return err
+}
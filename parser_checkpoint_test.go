@@ -0,0 +1,52 @@
+package textparser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParser_MarkReset(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("key = value")
+	mark := p.Mark()
+	a.Equal(0, mark.Index())
+
+	p.MustSkipString("key").StartCapture().MustSkipString(" = ")
+	p.Reset(mark)
+	a.Equal(0, p.CurrentIndex())
+	a.True(p.LookingAtString("key"))
+}
+
+func TestParser_Attempt(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("role: CEO")
+	p.MustSkipString("role").StartCapture()
+
+	// failing alternative is rolled back including the capture start
+	errAlternative := errors.New("not a number")
+	err := p.Attempt(func(p *Parser) error {
+		p.StartCapture().MustSkipString(": ").BeginCapture("number")
+		_, err := p.ReadInt()
+		if err != nil {
+			return errAlternative
+		}
+		return nil
+	})
+	a.ErrorIs(err, errAlternative)
+	a.Equal(4, p.CurrentIndex())
+	a.Empty(p.Captures())
+	_, err = p.EndCapture()
+	a.Error(err, "the capture begun in the failed attempt must not be open")
+
+	// successful alternative keeps the consumed input
+	err = p.Attempt(func(p *Parser) error {
+		return p.SkipString(": ")
+	})
+	a.Nil(err)
+	a.Equal(": ", p.Captured())
+	a.Equal("CEO", p.MustReadWord())
+}
@@ -0,0 +1,116 @@
+package textparser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RuneReader adapts a Parser to standard library functions expecting an io.RuneScanner, io.Reader or fmt.ScanState,
+// consuming input from the current position of the parser on. Create it using Parser.Reader.
+type RuneReader struct {
+	parser *Parser
+	// unreadable is set by ReadRune, and unreadAt is the parser index it left
+	unreadable bool
+	unreadAt   int
+	// bytes of a rune that did not fit into the buffer given to Read, which ends at index pendingAt
+	pending   []byte
+	pendingAt int
+}
+
+var (
+	_ io.RuneScanner = (*RuneReader)(nil)
+	_ io.Reader      = (*RuneReader)(nil)
+	_ fmt.ScanState  = (*RuneReader)(nil)
+)
+
+// Reader returns an adapter reading from the parser, see RuneReader.
+func (x *Parser) Reader() *RuneReader {
+	return &RuneReader{parser: x}
+}
+
+// ReadRune reads the next rune and returns it along with its size in UTF-8 encoding (io.RuneReader). At the end of
+// input, io.EOF is returned.
+func (x *RuneReader) ReadRune() (rune, int, error) {
+	x.unreadable = false
+	r, err := x.parser.ReadRune()
+	if err != nil {
+		return 0, 0, io.EOF
+	}
+	x.unreadable = true
+	x.unreadAt = x.parser.position
+	return r, utf8.RuneLen(r), nil
+}
+
+// UnreadRune undoes the last ReadRune (io.RuneScanner). It fails unless the last call to the reader was ReadRune and
+// the parser was not moved since.
+func (x *RuneReader) UnreadRune() error {
+	if !x.unreadable || x.unreadAt != x.parser.position {
+		return x.parser.NewParseError(InvalidArgument, "", "", errors.New("UnreadRune is only possible directly after ReadRune"))
+	}
+	x.unreadable = false
+	x.parser.position--
+	return nil
+}
+
+// Read reads the UTF-8 encoded input into p (io.Reader). The parser advances by every rune that is at least
+// partially written to p, the remaining bytes of a rune that did not fit are returned by the next call to Read,
+// unless the parser was moved in between.
+//
+// Note that readers like bufio.Reader or json.Decoder read ahead, so the parser usually advances further than what
+// they consumed.
+func (x *RuneReader) Read(p []byte) (int, error) {
+	x.unreadable = false
+	parser := x.parser
+	if x.pendingAt != parser.position {
+		x.pending = nil
+	}
+	n := copy(p, x.pending)
+	x.pending = x.pending[n:]
+
+	var buf [utf8.UTFMax]byte
+	for n < len(p) && len(x.pending) == 0 && parser.HasMore() {
+		size := utf8.EncodeRune(buf[:], parser.runeAt(parser.position))
+		parser.position++
+
+		copied := copy(p[n:], buf[:size])
+		n += copied
+		if copied < size {
+			x.pending = append([]byte{}, buf[copied:size]...)
+			x.pendingAt = parser.position
+		}
+	}
+
+	if n == 0 && len(p) > 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// SkipSpace skips any whitespace including newlines (fmt.ScanState).
+func (x *RuneReader) SkipSpace() {
+	x.unreadable = false
+	x.parser.SkipWhile(unicode.IsSpace)
+}
+
+// Token optionally skips whitespace and then reads the runes satisfying f, or non-whitespace runes if f is nil
+// (fmt.ScanState). The returned slice is not shared with the parser.
+func (x *RuneReader) Token(skipSpace bool, f func(rune) bool) ([]byte, error) {
+	if skipSpace {
+		x.SkipSpace()
+	}
+	x.unreadable = false
+	if f == nil {
+		f = func(r rune) bool {
+			return !unicode.IsSpace(r)
+		}
+	}
+	return []byte(x.parser.ReadWhile(f)), nil
+}
+
+// Width always reports that no width is set (fmt.ScanState).
+func (x *RuneReader) Width() (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,126 @@
+package textparser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParser_RuneScanner(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("öx")
+	reader := p.Reader()
+	r, size, err := reader.ReadRune()
+	a.Nil(err)
+	a.Equal('ö', r)
+	a.Equal(2, size)
+
+	a.Nil(reader.UnreadRune())
+	a.Equal(0, p.CurrentIndex())
+	a.Error(reader.UnreadRune())
+
+	p.MustSkip(1)
+	a.Error(reader.UnreadRune(), "the last move was no ReadRune")
+
+	_, _, err = reader.ReadRune()
+	a.Nil(err)
+	reader.SkipSpace()
+	a.Error(reader.UnreadRune(), "the last call to the reader was no ReadRune")
+
+	_, _, err = reader.ReadRune()
+	a.Equal(io.EOF, err)
+}
+
+func TestParser_ReadRune(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("ö")
+	r, err := p.ReadRune()
+	a.Nil(err)
+	a.Equal('ö', r)
+
+	_, err = p.ReadRune()
+	var parseErr *ParseError
+	a.True(errors.As(err, &parseErr))
+	a.Equal(EndOfInput, parseErr.Kind)
+}
+
+func TestParser_Fscan(t *testing.T) {
+	a := assert.New(t)
+
+	var (
+		name  string
+		count int
+		ratio float64
+	)
+	p := NewParser("widgets 42 0.5; rest")
+	n, err := fmt.Fscan(p.Reader(), &name, &count, &ratio)
+	a.Nil(err)
+	a.Equal(3, n)
+	a.Equal("widgets", name)
+	a.Equal(42, count)
+	a.Equal(0.5, ratio)
+	a.True(p.LookingAtString("; rest"), p.CurrentContext())
+}
+
+func TestParser_Read(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("aöb")
+	reader := p.Reader()
+	buf := make([]byte, 2)
+
+	n, err := reader.Read(buf)
+	a.Nil(err)
+	a.Equal(2, n)
+	a.Equal([]byte{'a', "ö"[0]}, buf)
+	a.Equal(2, p.CurrentIndex())
+
+	n, err = reader.Read(buf)
+	a.Nil(err)
+	a.Equal(2, n)
+	a.Equal([]byte{"ö"[1], 'b'}, buf)
+
+	_, err = reader.Read(buf)
+	a.Equal(io.EOF, err)
+
+	// the rest of a rune is dropped if the parser was moved
+	p = NewParser("öa")
+	reader = p.Reader()
+	n, _ = reader.Read(buf[:1])
+	a.Equal(1, n)
+	p.MustSkip(1)
+	n, err = reader.Read(buf)
+	a.Equal(io.EOF, err)
+	a.Equal(0, n)
+
+	var value struct {
+		Key string `json:"key"`
+	}
+	p = NewParser(`{"key": "välue"}`)
+	a.Nil(json.NewDecoder(p.Reader()).Decode(&value))
+	a.Equal("välue", value.Key)
+	a.True(p.IsExhausted())
+}
+
+func TestParser_ScanState(t *testing.T) {
+	a := assert.New(t)
+
+	var state fmt.ScanState = NewParser("  \n word next").Reader()
+	token, err := state.Token(true, nil)
+	a.Nil(err)
+	a.Equal("word", string(token))
+
+	_, ok := state.Width()
+	a.False(ok)
+}
+
+func TestEndOfInputError_IsEOF(t *testing.T) {
+	_, err := NewParser("").ReadWord()
+	assert.True(t, errors.Is(err, io.EOF))
+}
@@ -2,17 +2,13 @@ package textparser
 
 import (
 	"errors"
+	"strings"
 	"unicode"
-	"unicode/utf8"
 )
 
 // LookingAtString determines if from the current position, the next runes would equal the expected string provided.
 func (x *Parser) LookingAtString(expected string) bool {
-	result, err := x.GetNext(utf8.RuneCountInString(expected))
-	if errors.Is(err, EndOfInputError{}) {
-		return false
-	}
-	return result == expected
+	return strings.HasPrefix(x.input[x.byteOffset(x.position):], expected)
 }
 
 func (x *Parser) LookingAtRune(r rune) bool {
@@ -46,11 +42,23 @@ func (x *Parser) LookingAtDigit() bool {
 }
 
 func (x *Parser) LookingAtLetter() bool {
+	return x.LookingAtFunc(unicode.IsLetter)
+}
+
+// LookingAtFunc determines if the next rune satisfies pred, e.g. unicode.IsPunct or In(unicode.Greek).
+func (x *Parser) LookingAtFunc(pred func(rune) bool) bool {
 	nextRune, err := x.GetNextRune()
 	if errors.Is(err, EndOfInputError{}) {
 		return false
 	}
-	return unicode.IsLetter(nextRune)
+	return pred(nextRune)
+}
+
+// In returns a predicate for runes contained in any of the tables, for use with LookingAtFunc, ReadWhile and friends.
+func In(tables ...*unicode.RangeTable) func(rune) bool {
+	return func(r rune) bool {
+		return unicode.In(r, tables...)
+	}
 }
 
 func (x *Parser) MustGetNext(runeCount int) string {
@@ -66,14 +74,14 @@ func (x *Parser) GetNext(runeCount int) (string, error) {
 	if x.RemainingRuneCount() < runeCount {
 		return "", EndOfInputError{}
 	}
-	return string(x.input[x.position : x.position+runeCount]), nil
+	return x.slice(x.position, x.position+runeCount), nil
 }
 
 func (x *Parser) GetNextMax(runeCount int) string {
 	if runeCount > x.RemainingRuneCount() {
 		runeCount = x.RemainingRuneCount()
 	}
-	return string(x.input[x.position : x.position+runeCount])
+	return x.slice(x.position, x.position+runeCount)
 }
 
 func (x *Parser) MustGetNextRune() rune {
@@ -89,17 +97,13 @@ func (x *Parser) GetNextRune() (rune, error) {
 		var empty rune
 		return empty, EndOfInputError{}
 	}
-	return x.input[x.position], nil
+	return x.runeAt(x.position), nil
 }
 
 func (x *Parser) MustGetNextWord() string {
-	// save position
-	pos := x.position
-
+	mark := x.Mark()
 	result := x.MustReadWord()
-
-	// reset position
-	x.position = pos
+	x.Reset(mark)
 
 	return result
 }
@@ -3,11 +3,12 @@ package textparser
 import (
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"unicode"
 )
 
 func TestParser_LookingAtRune(t *testing.T) {
 	type fields struct {
-		input    []rune
+		input    string
 		position int
 	}
 	type args struct {
@@ -22,7 +23,7 @@ func TestParser_LookingAtRune(t *testing.T) {
 		{
 			name: "basic",
 			fields: fields{
-				input:    []rune("a b"),
+				input:    "a b",
 				position: 0,
 			},
 			args: args{'a'},
@@ -41,11 +42,11 @@ func TestParser_LookingAtRune(t *testing.T) {
 }
 
 func TestParser_LookingAtWhitespace(t *testing.T) {
-	basicInput := []rune(`a b	c
-d`)
+	basicInput := `a b	c
+d`
 
 	type fields struct {
-		input    []rune
+		input    string
 		position int
 	}
 	tests := []struct {
@@ -106,10 +107,10 @@ d`)
 }
 
 func TestParser_LookingAtDigit(t *testing.T) {
-	basicInput := []rune(`a 4ö3`)
+	basicInput := `a 4ö3`
 
 	type fields struct {
-		input    []rune
+		input    string
 		position int
 	}
 	tests := []struct {
@@ -154,10 +155,10 @@ func TestParser_LookingAtDigit(t *testing.T) {
 }
 
 func TestParser_LookingAtLetter(t *testing.T) {
-	basicInput := []rune(`a 4b3`)
+	basicInput := `a 4b3`
 
 	type fields struct {
-		input    []rune
+		input    string
 		position int
 	}
 	tests := []struct {
@@ -200,3 +201,15 @@ func TestParser_LookingAtLetter(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_LookingAtFunc(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("Ωa")
+	a.True(p.LookingAtFunc(unicode.IsUpper))
+	a.True(p.LookingAtFunc(In(unicode.Greek, unicode.Cyrillic)))
+	a.False(p.LookingAtFunc(In(unicode.Latin)))
+
+	p.MustSkip(2)
+	a.False(p.LookingAtFunc(func(r rune) bool { return true }))
+}
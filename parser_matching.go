@@ -2,6 +2,8 @@ package textparser
 
 import (
 	"fmt"
+	"strconv"
+	"unicode/utf8"
 )
 
 func (x *Parser) ReadToMatchingString(open, close string) (string, error) {
@@ -13,16 +15,24 @@ func (x *Parser) ReadToMatchingString(open, close string) (string, error) {
 		remainingRuneCount = x.RemainingRuneCount()
 		err                error
 	)
+	if err := x.checkDepth(openCount); err != nil {
+		return "", err
+	}
 
 	for {
+		err = x.Step()
+		if err != nil {
+			x.position = oldIndex
+			return "", err
+		}
 		if differentDelims {
 			if openCount < 0 {
 				x.position = oldIndex
-				return "", fmt.Errorf("could not find closing rune %s, because they were used unbalanced, closed before opened (rune %s)", string(close), string(open))
+				return "", x.NewParseError(Unbalanced, "closing "+strconv.Quote(close), "", fmt.Errorf("closed before opened (%q)", open))
 			}
 			if runeCount > remainingRuneCount {
 				x.position = oldIndex
-				return "", fmt.Errorf("could not find closing rune %s, input exhausted", string(close))
+				return "", x.NewParseErrorAt(x.length(), EndOfInput, "closing "+strconv.Quote(close), "", nil)
 			}
 		}
 
@@ -47,16 +57,26 @@ func (x *Parser) ReadToMatchingString(open, close string) (string, error) {
 				return "", err
 			}
 			openCount++
+			err = x.checkDepth(openCount)
+			if err != nil {
+				x.position = oldIndex
+				return "", err
+			}
 			continue
 		}
 		if x.IsExhausted() {
 			x.position = oldIndex
-			return "", fmt.Errorf("could not find closing rune %s, input exhausted", string(close))
+			return "", x.NewParseErrorAt(x.length(), EndOfInput, "closing "+strconv.Quote(close), "", nil)
 		}
 
 		x.MustSkip(1)
 	}
-	return x.MustExtract(oldIndex, x.position), nil
+	err = x.checkTokenLength(x.position - oldIndex)
+	if err != nil {
+		x.position = oldIndex
+		return "", err
+	}
+	return x.slice(oldIndex, x.position), nil
 }
 
 func (x *Parser) MustReadToMatchingString(open, close string) string {
@@ -69,11 +89,11 @@ func (x *Parser) MustReadToMatchingString(open, close string) string {
 
 func (x *Parser) ReadToMatchingStringSkipDelims(open, close string) (string, error) {
 	if !x.LookingAtString(open) {
-		return "", fmt.Errorf("could not find opening string %s, %s", open, x.CurrentContext())
+		return "", x.Unexpected("opening "+strconv.Quote(open), utf8.RuneCountInString(open))
 	}
 	err := x.SkipString(open)
 	if err != nil {
-		return "", fmt.Errorf("could not skip opening string %s, %s", open, x.CurrentContext())
+		return "", err
 	}
 	content, err := x.ReadToMatchingString(open, close)
 	if err != nil {
@@ -81,11 +101,11 @@ func (x *Parser) ReadToMatchingStringSkipDelims(open, close string) (string, err
 	}
 
 	if !x.LookingAtString(close) {
-		return "", fmt.Errorf("could not find opening rune %s, %s", close, x.CurrentContext())
+		return "", x.Unexpected("closing "+strconv.Quote(close), utf8.RuneCountInString(close))
 	}
 	err = x.SkipString(close)
 	if err != nil {
-		return "", fmt.Errorf("could not skip opening rune %s, %s", close, x.CurrentContext())
+		return "", err
 	}
 
 	return content, nil
@@ -108,26 +128,32 @@ func (x *Parser) ReadToMatchingRune(open, close rune) (string, error) {
 		remainingRuneCount = x.RemainingRuneCount()
 		newPos             int
 	)
+	if err := x.checkDepth(openCount); err != nil {
+		return "", err
+	}
 
 	for {
 		if openCount == 0 {
 			break
 		}
+		if err := x.Step(); err != nil {
+			return "", err
+		}
 
 		if differentRunes {
 			if openCount < 0 {
-				return "", fmt.Errorf("could not find closing rune %s, because they were used unbalanced, closed before opened (rune %s)", string(close), string(open))
+				return "", x.NewParseError(Unbalanced, "closing "+strconv.QuoteRune(close), "", fmt.Errorf("closed before opened (%q)", open))
 			}
 			if runeCount > remainingRuneCount {
-				return "", fmt.Errorf("could not find closing rune %s, input exhausted", string(close))
+				return "", x.NewParseErrorAt(x.length(), EndOfInput, "closing "+strconv.QuoteRune(close), "", nil)
 			}
 		}
 
 		newPos = x.position + runeCount
-		if len(x.input) <= newPos {
-			return "", fmt.Errorf("could not find closing rune %s, input exhausted", string(close))
+		if x.length() <= newPos {
+			return "", x.NewParseErrorAt(x.length(), EndOfInput, "closing "+strconv.QuoteRune(close), "", nil)
 		}
-		r = x.input[newPos]
+		r = x.runeAt(newPos)
 
 		if differentRunes {
 			switch r {
@@ -136,6 +162,9 @@ func (x *Parser) ReadToMatchingRune(open, close rune) (string, error) {
 				openCount--
 			case open:
 				openCount++
+				if err := x.checkDepth(openCount); err != nil {
+					return "", err
+				}
 			}
 		} else {
 			if r == close {
@@ -145,7 +174,7 @@ func (x *Parser) ReadToMatchingRune(open, close rune) (string, error) {
 
 		runeCount++
 	}
-	return string(x.MustReadRunes(max(0, runeCount-1))), nil
+	return x.readRunes(max(0, runeCount-1))
 }
 
 func (x *Parser) MustReadToMatchingRune(open, close rune) string {
@@ -158,11 +187,11 @@ func (x *Parser) MustReadToMatchingRune(open, close rune) string {
 
 func (x *Parser) ReadToMatchingRuneSkipDelims(open, close rune) (string, error) {
 	if !x.LookingAtRune(open) {
-		return "", fmt.Errorf("could not find opening rune %s, %s", string(open), x.CurrentContext())
+		return "", x.Unexpected("opening "+strconv.QuoteRune(open), 1)
 	}
 	err := x.Skip(1)
 	if err != nil {
-		return "", fmt.Errorf("could not skip opening rune %s, %s", string(open), x.CurrentContext())
+		return "", err
 	}
 	content, err := x.ReadToMatchingRune(open, close)
 	if err != nil {
@@ -170,11 +199,11 @@ func (x *Parser) ReadToMatchingRuneSkipDelims(open, close rune) (string, error)
 	}
 
 	if !x.LookingAtRune(close) {
-		return "", fmt.Errorf("could not find opening rune %s, %s", string(close), x.CurrentContext())
+		return "", x.Unexpected("closing "+strconv.QuoteRune(close), 1)
 	}
 	err = x.Skip(1)
 	if err != nil {
-		return "", fmt.Errorf("could not skip opening rune %s, %s", string(close), x.CurrentContext())
+		return "", err
 	}
 
 	return content, nil
@@ -198,23 +227,29 @@ func (x *Parser) ReadToMatchingRuneEscaped(open, close, escape rune) (string, er
 		remainingRuneCount = x.RemainingRuneCount()
 		result             = ""
 	)
+	if err := x.checkDepth(openCount); err != nil {
+		return "", err
+	}
 
 	for {
-		if differentRunes {
-			if openCount < 0 {
-				return "", fmt.Errorf("could not find closing rune %v, because they were used unbalanced, closed before opened (rune %v)", close, open)
-			}
-			if runeCount > remainingRuneCount {
-				return "", fmt.Errorf("could not find closing rune %v, input exhausted", close)
-			}
+		if err := x.Step(); err != nil {
+			return "", err
+		}
+		if differentRunes && openCount < 0 {
+			return "", x.NewParseError(Unbalanced, "closing "+strconv.QuoteRune(close), "", fmt.Errorf("closed before opened (%q)", open))
+		}
+		if runeCount >= remainingRuneCount {
+			return "", x.NewParseErrorAt(x.length(), EndOfInput, "closing "+strconv.QuoteRune(close), "", nil)
 		}
 
-		r = x.input[x.position+runeCount]
+		r = x.runeAt(x.position + runeCount)
 
-		// check the escaping
-		rBefore = x.input[x.position+runeCount-1]
-		if rBefore == escape && (r == close || r == open) {
-			result = result[0:len(result)-1] + string(r)
+		// check the escaping, the escape rune was added to the result already
+		if runeCount > 0 {
+			rBefore = x.runeAt(x.position + runeCount - 1)
+		}
+		if runeCount > 0 && rBefore == escape && (r == close || r == open) {
+			result = result[0:len(result)-utf8.RuneLen(escape)] + string(r)
 			runeCount++
 			continue
 		}
@@ -226,6 +261,9 @@ func (x *Parser) ReadToMatchingRuneEscaped(open, close, escape rune) (string, er
 				openCount--
 			case open:
 				openCount++
+				if err := x.checkDepth(openCount); err != nil {
+					return "", err
+				}
 			}
 		} else {
 			if r == close {
@@ -241,6 +279,9 @@ func (x *Parser) ReadToMatchingRuneEscaped(open, close, escape rune) (string, er
 		runeCount++
 	}
 
+	if err := x.checkTokenLength(runeCount); err != nil {
+		return "", err
+	}
 	x.position += runeCount
 	return result, nil
 }
@@ -255,11 +296,11 @@ func (x *Parser) MustReadToMatchingRuneEscaped(open, close, escape rune) string
 
 func (x *Parser) ReadToMatchingRuneEscapedSkipDelims(open, close, escape rune) (string, error) {
 	if !x.LookingAtRune(open) {
-		return "", fmt.Errorf("could not find opening rune %s, %s", string(open), x.CurrentContext())
+		return "", x.Unexpected("opening "+strconv.QuoteRune(open), 1)
 	}
 	err := x.Skip(1)
 	if err != nil {
-		return "", fmt.Errorf("could not skip opening rune %s, %s", string(open), x.CurrentContext())
+		return "", err
 	}
 	content, err := x.ReadToMatchingRuneEscaped(open, close, escape)
 	if err != nil {
@@ -267,11 +308,11 @@ func (x *Parser) ReadToMatchingRuneEscapedSkipDelims(open, close, escape rune) (
 	}
 
 	if !x.LookingAtRune(close) {
-		return "", fmt.Errorf("could not find closing rune %s, %s", string(close), x.CurrentContext())
+		return "", x.Unexpected("closing "+strconv.QuoteRune(close), 1)
 	}
 	err = x.Skip(1)
 	if err != nil {
-		return "", fmt.Errorf("could not skip closing rune %s, %s", string(close), x.CurrentContext())
+		return "", err
 	}
 
 	return content, nil
@@ -284,3 +325,277 @@ func (x *Parser) MustReadToMatchingRuneEscapedSkipDelims(open, close, escape run
 	}
 	return result
 }
+
+// QuotePair describes a quoted string for MatchingOptions. Escape escapes the rune following it within the quotes, 0
+// for none.
+type QuotePair struct {
+	Open   string
+	Close  string
+	Escape rune
+}
+
+// CommentPair describes a block comment for MatchingOptions, like /* and */.
+type CommentPair struct {
+	Open  string
+	Close string
+}
+
+// MatchingOptions describes parts of the input whose content is skipped by ReadToMatchingWithOptions, so delimiters
+// in them are not counted.
+type MatchingOptions struct {
+	Quotes        []QuotePair
+	LineComments  []string
+	BlockComments []CommentPair
+}
+
+// ReadToMatchingWithOptions is like ReadToMatchingString, but skips quoted strings and comments described by opts.
+// For example, with a QuotePair{`"`, `"`, '\\'}, the content of `{ "a}" }` is read completely.
+func (x *Parser) ReadToMatchingWithOptions(open, close string, opts MatchingOptions) (string, error) {
+	var (
+		start = x.position
+		depth = 1
+	)
+	if err := x.checkDepth(depth); err != nil {
+		return "", err
+	}
+	for {
+		if x.IsExhausted() {
+			x.position = start
+			return "", x.NewParseErrorAt(x.length(), EndOfInput, "closing "+strconv.Quote(close), "", nil)
+		}
+		if err := x.Step(); err != nil {
+			x.position = start
+			return "", err
+		}
+
+		skipped, err := x.skipQuotesAndComments(opts)
+		if err != nil {
+			x.position = start
+			return "", err
+		}
+		if skipped {
+			continue
+		}
+
+		switch {
+		case x.LookingAtString(close):
+			depth--
+			if depth == 0 {
+				return x.finishMatching(start)
+			}
+			x.MustSkip(utf8.RuneCountInString(close))
+		case x.LookingAtString(open):
+			depth++
+			if err := x.checkDepth(depth); err != nil {
+				x.position = start
+				return "", err
+			}
+			x.MustSkip(utf8.RuneCountInString(open))
+		default:
+			x.MustSkip(1)
+		}
+	}
+}
+
+func (x *Parser) MustReadToMatchingWithOptions(open, close string, opts MatchingOptions) string {
+	result, err := x.ReadToMatchingWithOptions(open, close, opts)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// ReadToMatchingWithOptionsSkipDelims is like ReadToMatchingWithOptions, but expects to be at open and skips both
+// delimiters.
+func (x *Parser) ReadToMatchingWithOptionsSkipDelims(open, close string, opts MatchingOptions) (string, error) {
+	start := x.position
+	err := x.SkipString(open)
+	if err != nil {
+		return "", err
+	}
+	content, err := x.ReadToMatchingWithOptions(open, close, opts)
+	if err != nil {
+		x.position = start
+		return "", err
+	}
+	x.MustSkip(utf8.RuneCountInString(close))
+	return content, nil
+}
+
+func (x *Parser) MustReadToMatchingWithOptionsSkipDelims(open, close string, opts MatchingOptions) string {
+	result, err := x.ReadToMatchingWithOptionsSkipDelims(open, close, opts)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// skipQuotesAndComments skips a quoted string or comment starting at the current position, if any.
+func (x *Parser) skipQuotesAndComments(opts MatchingOptions) (bool, error) {
+	for _, marker := range opts.LineComments {
+		if x.LookingAtString(marker) {
+			return true, x.SkipRestOfLine()
+		}
+	}
+
+	for _, comment := range opts.BlockComments {
+		if !x.LookingAtString(comment.Open) {
+			continue
+		}
+		start := x.position
+		x.MustSkip(utf8.RuneCountInString(comment.Open))
+		end, err := x.findNext(comment.Close)
+		if err != nil {
+			return true, x.NewParseErrorAt(start, EndOfInput, "end of comment "+strconv.Quote(comment.Close), "", nil)
+		}
+		x.position = end + utf8.RuneCountInString(comment.Close)
+		return true, nil
+	}
+
+	for _, quote := range opts.Quotes {
+		if !x.LookingAtString(quote.Open) {
+			continue
+		}
+		start := x.position
+		x.MustSkip(utf8.RuneCountInString(quote.Open))
+		for !x.LookingAtString(quote.Close) {
+			r, err := x.ReadRune()
+			if err == nil && r == quote.Escape && quote.Escape != 0 {
+				_, err = x.ReadRune()
+			}
+			if err != nil {
+				return true, x.NewParseErrorAt(start, EndOfInput, "closing quote "+strconv.Quote(quote.Close), "", nil)
+			}
+		}
+		x.MustSkip(utf8.RuneCountInString(quote.Close))
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// BracketPair is a pair of brackets for ReadBalanced.
+type BracketPair struct {
+	Open  rune
+	Close rune
+}
+
+// BracketMismatchError describes brackets that don't match. It is wrapped in a *ParseError of kind Unbalanced.
+type BracketMismatchError struct {
+	Open         rune
+	OpenPosition Position
+	// Close is the bracket found instead of the one matching Open, 0 if the input ended before.
+	Close         rune
+	ClosePosition Position
+}
+
+func (x *BracketMismatchError) Error() string {
+	if x.Close == 0 {
+		return fmt.Sprintf("%q at %s is not closed", x.Open, x.OpenPosition)
+	}
+	return fmt.Sprintf("%q at %s is closed by %q at %s", x.Open, x.OpenPosition, x.Close, x.ClosePosition)
+}
+
+// ReadBalanced reads a group of brackets starting at the current position, like "(a[1] + {b})", including the
+// brackets. Brackets of all the given pairs can nest, and every closing bracket must match the innermost open one.
+// Mismatches are reported as *ParseError of kind Unbalanced wrapping a *BracketMismatchError.
+func (x *Parser) ReadBalanced(pairs []BracketPair) (string, error) {
+	return x.ReadBalancedWithOptions(pairs, MatchingOptions{})
+}
+
+func (x *Parser) MustReadBalanced(pairs []BracketPair) string {
+	result, err := x.ReadBalanced(pairs)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// ReadBalancedWithOptions is like ReadBalanced, but skips quoted strings and comments described by opts, see
+// ReadToMatchingWithOptions.
+func (x *Parser) ReadBalancedWithOptions(pairs []BracketPair, opts MatchingOptions) (string, error) {
+	var (
+		start   = x.position
+		closing = make(map[rune]rune, len(pairs))
+		isClose = make(map[rune]bool, len(pairs))
+	)
+	for _, pair := range pairs {
+		closing[pair.Open] = pair.Close
+		isClose[pair.Close] = true
+	}
+
+	r, err := x.GetNextRune()
+	if _, ok := closing[r]; err != nil || !ok {
+		return "", x.Unexpected("opening bracket", 1)
+	}
+
+	// indices of the open brackets
+	var stack []int
+	for {
+		if err := x.Step(); err != nil {
+			x.position = start
+			return "", err
+		}
+		skipped, err := x.skipQuotesAndComments(opts)
+		if err != nil {
+			x.position = start
+			return "", err
+		}
+		if skipped {
+			continue
+		}
+
+		r, err := x.GetNextRune()
+		if err != nil {
+			open := stack[len(stack)-1]
+			mismatch := &BracketMismatchError{
+				Open:          x.runeAt(open),
+				OpenPosition:  x.PositionAt(open),
+				ClosePosition: x.CurrentPosition(),
+			}
+			x.position = start
+			return "", x.NewParseErrorAt(x.length(), Unbalanced, "closing "+strconv.QuoteRune(closing[mismatch.Open]), "", mismatch)
+		}
+
+		// a bracket opening and closing like | is treated as closing when it's open
+		if len(stack) > 0 && r == closing[x.runeAt(stack[len(stack)-1])] {
+			x.MustSkip(1)
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return x.finishMatching(start)
+			}
+			continue
+		}
+		if _, ok := closing[r]; ok {
+			stack = append(stack, x.position)
+			if err := x.checkDepth(len(stack)); err != nil {
+				x.position = start
+				return "", err
+			}
+			x.MustSkip(1)
+			continue
+		}
+		if isClose[r] {
+			open := stack[len(stack)-1]
+			mismatch := &BracketMismatchError{
+				Open:          x.runeAt(open),
+				OpenPosition:  x.PositionAt(open),
+				Close:         r,
+				ClosePosition: x.CurrentPosition(),
+			}
+			pos := x.position
+			x.position = start
+			return "", x.NewParseErrorAt(pos, Unbalanced, "closing "+strconv.QuoteRune(closing[mismatch.Open]), string(r), mismatch)
+		}
+		x.MustSkip(1)
+	}
+}
+
+// finishMatching returns the input read since start, resetting the parser if it exceeds MaxTokenLength.
+func (x *Parser) finishMatching(start int) (string, error) {
+	if err := x.checkTokenLength(x.position - start); err != nil {
+		x.position = start
+		return "", err
+	}
+	return x.slice(start, x.position), nil
+}
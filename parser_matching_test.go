@@ -1,6 +1,7 @@
 package textparser
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -8,7 +9,7 @@ import (
 
 func TestParser_MustReadToMatchingString(t *testing.T) {
 	type fields struct {
-		input    []rune
+		input    string
 		position int
 	}
 	type args struct {
@@ -25,7 +26,7 @@ func TestParser_MustReadToMatchingString(t *testing.T) {
 		{
 			name: "basic",
 			fields: fields{
-				input:    []rune("((abc))"),
+				input:    "((abc))",
 				position: 2,
 			},
 			args: args{
@@ -49,7 +50,7 @@ func TestParser_MustReadToMatchingString(t *testing.T) {
 
 func TestParser_MustReadToMatchingStringSkipDelims(t *testing.T) {
 	type fields struct {
-		input    []rune
+		input    string
 		position int
 	}
 	type args struct {
@@ -66,7 +67,7 @@ func TestParser_MustReadToMatchingStringSkipDelims(t *testing.T) {
 		{
 			name: "basic",
 			fields: fields{
-				input:    []rune("((abc))d"),
+				input:    "((abc))d",
 				position: 0,
 			},
 			args: args{
@@ -79,7 +80,7 @@ func TestParser_MustReadToMatchingStringSkipDelims(t *testing.T) {
 		{
 			name: "quote (same open and close rune)",
 			fields: fields{
-				input:    []rune("he said 'yes', sir"),
+				input:    "he said 'yes', sir",
 				position: 8,
 			},
 			args: args{
@@ -104,7 +105,7 @@ func TestParser_MustReadToMatchingStringSkipDelims(t *testing.T) {
 
 func TestParser_MustReadToMatchingRune(t *testing.T) {
 	type fields struct {
-		input    []rune
+		input    string
 		position int
 	}
 	type args struct {
@@ -121,7 +122,7 @@ func TestParser_MustReadToMatchingRune(t *testing.T) {
 		{
 			name: "basic",
 			fields: fields{
-				input:    []rune("(abc)"),
+				input:    "(abc)",
 				position: 1,
 			},
 			args: args{
@@ -133,7 +134,7 @@ func TestParser_MustReadToMatchingRune(t *testing.T) {
 		{
 			name: "basic rune",
 			fields: fields{
-				input:    []rune("ß{ÄÖü}?-#Ü"),
+				input:    "ß{ÄÖü}?-#Ü",
 				position: 2,
 			},
 			args: args{
@@ -145,7 +146,7 @@ func TestParser_MustReadToMatchingRune(t *testing.T) {
 		{
 			name: "multiline",
 			fields: fields{
-				input:    []rune("-\n\n-"),
+				input:    "-\n\n-",
 				position: 1,
 			},
 			args: args{
@@ -157,7 +158,7 @@ func TestParser_MustReadToMatchingRune(t *testing.T) {
 		{
 			name: "empty",
 			fields: fields{
-				input:    []rune("{}"),
+				input:    "{}",
 				position: 1,
 			},
 			args: args{
@@ -169,7 +170,7 @@ func TestParser_MustReadToMatchingRune(t *testing.T) {
 		{
 			name: "repeated",
 			fields: fields{
-				input:    []rune("[ab]c[de]f)"),
+				input:    "[ab]c[de]f)",
 				position: 1,
 			},
 			args: args{
@@ -182,7 +183,7 @@ func TestParser_MustReadToMatchingRune(t *testing.T) {
 		{
 			name: "nested",
 			fields: fields{
-				input:    []rune("([a[b]c]def)"),
+				input:    "([a[b]c]def)",
 				position: 2,
 			},
 			args: args{
@@ -195,7 +196,7 @@ func TestParser_MustReadToMatchingRune(t *testing.T) {
 		{
 			name: "not inside",
 			fields: fields{
-				input:    []rune("]def"),
+				input:    "]def",
 				position: 0,
 			},
 			args: args{
@@ -208,7 +209,7 @@ func TestParser_MustReadToMatchingRune(t *testing.T) {
 		{
 			name: "quote (same open and close rune)",
 			fields: fields{
-				input:    []rune("he said 'yes'"),
+				input:    "he said 'yes'",
 				position: 9,
 			},
 			args: args{
@@ -232,7 +233,7 @@ func TestParser_MustReadToMatchingRune(t *testing.T) {
 
 func TestParser_MustReadToMatchingRuneSkipDelims(t *testing.T) {
 	type fields struct {
-		input    []rune
+		input    string
 		position int
 	}
 	type args struct {
@@ -249,7 +250,7 @@ func TestParser_MustReadToMatchingRuneSkipDelims(t *testing.T) {
 		{
 			name: "basic",
 			fields: fields{
-				input:    []rune("(abc)d"),
+				input:    "(abc)d",
 				position: 0,
 			},
 			args: args{
@@ -262,7 +263,7 @@ func TestParser_MustReadToMatchingRuneSkipDelims(t *testing.T) {
 		{
 			name: "quote (same open and close rune)",
 			fields: fields{
-				input:    []rune("he said 'yes', sir"),
+				input:    "he said 'yes', sir",
 				position: 8,
 			},
 			args: args{
@@ -287,7 +288,7 @@ func TestParser_MustReadToMatchingRuneSkipDelims(t *testing.T) {
 
 func TestParser_ReadToMatchingRuneEscaped(t *testing.T) {
 	type fields struct {
-		input    []rune
+		input    string
 		position int
 	}
 	type args struct {
@@ -305,7 +306,7 @@ func TestParser_ReadToMatchingRuneEscaped(t *testing.T) {
 		{
 			name: "nothing",
 			fields: fields{
-				input:    []rune(`my "" is`),
+				input:    `my "" is`,
 				position: 4,
 			},
 			args: args{
@@ -319,7 +320,7 @@ func TestParser_ReadToMatchingRuneEscaped(t *testing.T) {
 		{
 			name: "escape only",
 			fields: fields{
-				input:    []rune(`my (\)) is`),
+				input:    `my (\)) is`,
 				position: 4,
 			},
 			args: args{
@@ -333,7 +334,7 @@ func TestParser_ReadToMatchingRuneEscaped(t *testing.T) {
 		{
 			name: "basic",
 			fields: fields{
-				input:    []rune(`my "best \" attempt" is`),
+				input:    `my "best \" attempt" is`,
 				position: 4,
 			},
 			args: args{
@@ -359,9 +360,38 @@ func TestParser_ReadToMatchingRuneEscaped(t *testing.T) {
 	}
 }
 
+func TestParser_ReadToMatchingRuneEscapedUnterminated(t *testing.T) {
+	tests := []struct {
+		input    string
+		position int
+		open     rune
+		close    rune
+	}{
+		{``, 0, '"', '"'},
+		{`"`, 1, '"', '"'},
+		{`"abc`, 1, '"', '"'},
+		{`"abc\"`, 1, '"', '"'},
+		{`(`, 1, '(', ')'},
+		{`(a(b)`, 1, '(', ')'},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			a := assert.New(t)
+
+			x := &Parser{
+				input:    tt.input,
+				position: tt.position,
+			}
+			_, err := x.ReadToMatchingRuneEscaped(tt.open, tt.close, '\\')
+			a.ErrorIs(err, EndOfInputError{})
+			a.Equal(tt.position, x.CurrentIndex())
+		})
+	}
+}
+
 func TestParser_ReadToMatchingRuneEscapedSkipDelims(t *testing.T) {
 	type fields struct {
-		input    []rune
+		input    string
 		position int
 	}
 	type args struct {
@@ -379,7 +409,7 @@ func TestParser_ReadToMatchingRuneEscapedSkipDelims(t *testing.T) {
 		{
 			name: "nothing",
 			fields: fields{
-				input:    []rune(`my "" is`),
+				input:    `my "" is`,
 				position: 3,
 			},
 			args: args{
@@ -393,7 +423,7 @@ func TestParser_ReadToMatchingRuneEscapedSkipDelims(t *testing.T) {
 		{
 			name: "escape only",
 			fields: fields{
-				input:    []rune(`my (\)) is`),
+				input:    `my (\)) is`,
 				position: 3,
 			},
 			args: args{
@@ -407,7 +437,7 @@ func TestParser_ReadToMatchingRuneEscapedSkipDelims(t *testing.T) {
 		{
 			name: "basic",
 			fields: fields{
-				input:    []rune(`my "best \" attempt" is`),
+				input:    `my "best \" attempt" is`,
 				position: 3,
 			},
 			args: args{
@@ -432,3 +462,155 @@ func TestParser_ReadToMatchingRuneEscapedSkipDelims(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_ReadToMatchingWithOptionsSkipDelims(t *testing.T) {
+	codeOptions := MatchingOptions{
+		Quotes:        []QuotePair{{`"`, `"`, '\\'}, {"`", "`", 0}},
+		LineComments:  []string{"//"},
+		BlockComments: []CommentPair{{"/*", "*/"}},
+	}
+	tests := []struct {
+		name          string
+		input         string
+		want          string
+		wantErr       string
+		wantLookingAt string
+	}{
+		{
+			name:          "quoted delimiter",
+			input:         `{ "a}" } x`,
+			want:          ` "a}" `,
+			wantLookingAt: " x",
+		},
+		{
+			name:          "escaped quote",
+			input:         `{ "\"}" {} }`,
+			want:          ` "\"}" {} `,
+			wantLookingAt: "",
+		},
+		{
+			name:          "raw quote without escape",
+			input:         "{ `\\` } }",
+			want:          " `\\` ",
+			wantLookingAt: " }",
+		},
+		{
+			name:          "comments",
+			input:         "{ // }\n /* { */ }",
+			want:          " // }\n /* { */ ",
+			wantLookingAt: "",
+		},
+		{
+			name:    "unterminated quote",
+			input:   `{ "}`,
+			wantErr: `unexpected end of input at line 1, column 3, expected closing quote "\""`,
+		},
+		{
+			name:    "unterminated comment",
+			input:   `{ /* }`,
+			wantErr: `unexpected end of input at line 1, column 3, expected end of comment "*/"`,
+		},
+		{
+			name:    "unbalanced",
+			input:   `{ {} "}"`,
+			wantErr: `unexpected end of input at line 1, column 9, expected closing "}"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := NewParser(tt.input)
+			actual, err := x.ReadToMatchingWithOptionsSkipDelims("{", "}", codeOptions)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Equal(t, 0, x.CurrentIndex())
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, actual)
+			assert.Equal(t, tt.wantLookingAt, x.Remaining())
+		})
+	}
+}
+
+func TestParser_ReadBalanced(t *testing.T) {
+	brackets := []BracketPair{{'(', ')'}, {'[', ']'}, {'{', '}'}}
+	tests := []struct {
+		name          string
+		input         string
+		want          string
+		wantErr       string
+		wantMismatch  *BracketMismatchError
+		wantLookingAt string
+	}{
+		{
+			name:          "nested",
+			input:         "(a[1] + {b: (c)}) + d",
+			want:          "(a[1] + {b: (c)})",
+			wantLookingAt: " + d",
+		},
+		{
+			name:          "other brackets are ignored",
+			input:         "[<x>]",
+			want:          "[<x>]",
+			wantLookingAt: "",
+		},
+		{
+			name:    "not at an opening bracket",
+			input:   "a(b)",
+			wantErr: `unexpected input at line 1, column 1, expected opening bracket, found "a"`,
+		},
+		{
+			name:    "mismatch",
+			input:   "(a[b)]",
+			wantErr: `unbalanced delimiters at line 1, column 5, expected closing ']', found ")": '[' at line 1, column 3 is closed by ')' at line 1, column 5`,
+			wantMismatch: &BracketMismatchError{
+				Open:          '[',
+				OpenPosition:  Position{Offset: 2, Line: 1, Column: 3, ByteOffset: 2},
+				Close:         ')',
+				ClosePosition: Position{Offset: 4, Line: 1, Column: 5, ByteOffset: 4},
+			},
+		},
+		{
+			name:    "unclosed",
+			input:   "{a\n(b)",
+			wantErr: `unbalanced delimiters at line 2, column 4, expected closing '}': '{' at line 1, column 1 is not closed`,
+			wantMismatch: &BracketMismatchError{
+				Open:          '{',
+				OpenPosition:  Position{Offset: 0, Line: 1, Column: 1, ByteOffset: 0},
+				ClosePosition: Position{Offset: 6, Line: 2, Column: 4, ByteOffset: 6},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := NewParser(tt.input)
+			actual, err := x.ReadBalanced(brackets)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Equal(t, 0, x.CurrentIndex())
+
+				var mismatch *BracketMismatchError
+				assert.Equal(t, tt.wantMismatch != nil, errors.As(err, &mismatch))
+				assert.Equal(t, tt.wantMismatch, mismatch)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, actual)
+			assert.Equal(t, tt.wantLookingAt, x.Remaining())
+		})
+	}
+}
+
+func TestParser_ReadBalancedWithOptions(t *testing.T) {
+	a := assert.New(t)
+
+	x := NewParser(`f(")", [']'], /* ( */ x) rest`)
+	x.MustSkip(1)
+	actual, err := x.ReadBalancedWithOptions([]BracketPair{{'(', ')'}, {'[', ']'}}, MatchingOptions{
+		Quotes:        []QuotePair{{`"`, `"`, '\\'}, {"'", "'", '\\'}},
+		BlockComments: []CommentPair{{"/*", "*/"}},
+	})
+	a.Nil(err)
+	a.Equal(`(")", [']'], /* ( */ x)`, actual)
+	a.Equal(" rest", x.Remaining())
+}
@@ -0,0 +1,379 @@
+package textparser
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Number is the text of a numeric literal as read by ReadNumber. Convert it using its methods.
+type Number string
+
+// IsInteger determines if the number has neither a fraction nor an exponent.
+func (x Number) IsInteger() bool {
+	return !strings.ContainsAny(string(x), ".eE")
+}
+
+func (x Number) Int64() (int64, error) {
+	return strconv.ParseInt(string(x), 10, 64)
+}
+
+func (x Number) Float64() (float64, error) {
+	return strconv.ParseFloat(string(x), 64)
+}
+
+func (x Number) String() string {
+	return string(x)
+}
+
+// ReadInt64 reads a decimal integer with an optional sign.
+func (x *Parser) ReadInt64() (int64, error) {
+	text, err := x.scanNumberText(x.scanInteger(x.position, true), "integer")
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return 0, x.numberError("integer", text, err)
+	}
+	x.MustSkip(len(text))
+	return v, nil
+}
+
+func (x *Parser) MustReadInt64() int64 {
+	value, err := x.ReadInt64()
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// ReadUint64 reads a decimal integer that may have a plus sign, but no minus sign.
+func (x *Parser) ReadUint64() (uint64, error) {
+	text, err := x.scanNumberText(x.scanInteger(x.position, false), "unsigned integer")
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseUint(text, 10, 64)
+	if err != nil {
+		return 0, x.numberError("unsigned integer", text, err)
+	}
+	x.MustSkip(len(text))
+	return v, nil
+}
+
+func (x *Parser) MustReadUint64() uint64 {
+	value, err := x.ReadUint64()
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// ReadFloat reads a decimal floating point number like -1.5, +.5 or 2e-3. A dot is only read if digits follow it, so
+// "3." is read as 3 with the dot left unread. Infinity and NaN are not accepted, see ReadFloatWithInfNaN.
+func (x *Parser) ReadFloat() (float64, error) {
+	return x.readFloat(x.scanFloat(x.position))
+}
+
+func (x *Parser) MustReadFloat() float64 {
+	value, err := x.ReadFloat()
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// ReadFloatWithInfNaN is like ReadFloat, but additionally accepts "Inf", "Infinity" and "NaN" (case-insensitive, Inf
+// with an optional sign).
+func (x *Parser) ReadFloatWithInfNaN() (float64, error) {
+	end := x.scanInfNaN(x.position)
+	if end == x.position {
+		end = x.scanFloat(x.position)
+	}
+	return x.readFloat(end)
+}
+
+func (x *Parser) MustReadFloatWithInfNaN() float64 {
+	value, err := x.ReadFloatWithInfNaN()
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// ReadNumber reads an integer or floating point number and returns its text, so that the caller can decide on the
+// type to convert it to.
+func (x *Parser) ReadNumber() (Number, error) {
+	text, err := x.scanNumberText(x.scanFloat(x.position), "number")
+	if err != nil {
+		return "", err
+	}
+	x.MustSkip(len(text))
+	return Number(text), nil
+}
+
+func (x *Parser) MustReadNumber() Number {
+	value, err := x.ReadNumber()
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// ReadIntLiteral reads an integer literal in Go syntax: an optional sign, an optional base prefix (0x, 0o, 0b or a
+// leading 0 for octal) and digits that may be separated by underscores, e.g. 0x1F, 0o755, 0b1010 or 1_000_000.
+func (x *Parser) ReadIntLiteral() (int64, error) {
+	text, err := x.scanNumberText(x.scanIntLiteral(x.position), "integer literal")
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseInt(text, 0, 64)
+	if err != nil {
+		return 0, x.numberError("integer literal", text, err)
+	}
+	x.MustSkip(len(text))
+	return v, nil
+}
+
+func (x *Parser) MustReadIntLiteral() int64 {
+	value, err := x.ReadIntLiteral()
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// ReadIntBase reads an integer with an optional sign and digits in base (2 to 36, letters for digits above 9 may be
+// upper or lower case). Base 0 behaves like ReadIntLiteral.
+func (x *Parser) ReadIntBase(base int) (int64, error) {
+	if base == 0 {
+		return x.ReadIntLiteral()
+	}
+	if base < 2 || base > 36 {
+		return 0, x.NewParseError(InvalidArgument, "", "", fmt.Errorf("invalid base %d", base))
+	}
+
+	expected := fmt.Sprintf("base %d integer", base)
+	digitsStart := x.scanSign(x.position, true)
+	end := x.scanDigits(digitsStart, base, false)
+	if end == digitsStart {
+		end = x.position
+	}
+	text, err := x.scanNumberText(end, expected)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseInt(text, base, 64)
+	if err != nil {
+		return 0, x.numberError(expected, text, err)
+	}
+	x.MustSkip(len(text))
+	return v, nil
+}
+
+func (x *Parser) MustReadIntBase(base int) int64 {
+	value, err := x.ReadIntBase(base)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// ReadBigInt reads an integer literal like ReadIntLiteral, but without a size limit.
+func (x *Parser) ReadBigInt() (*big.Int, error) {
+	text, err := x.scanNumberText(x.scanIntLiteral(x.position), "integer literal")
+	if err != nil {
+		return nil, err
+	}
+	v, ok := new(big.Int).SetString(text, 0)
+	if !ok {
+		return nil, x.NewParseError(UnexpectedInput, "integer literal", text, nil)
+	}
+	x.MustSkip(len(text))
+	return v, nil
+}
+
+func (x *Parser) MustReadBigInt() *big.Int {
+	value, err := x.ReadBigInt()
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// readFloat reads the floating point number up to the index end.
+func (x *Parser) readFloat(end int) (float64, error) {
+	text, err := x.scanNumberText(end, "floating point number")
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, x.numberError("floating point number", text, err)
+	}
+	x.MustSkip(len(text))
+	return v, nil
+}
+
+// scanNumberText returns the input from the current position to end, or an error if that is empty.
+func (x *Parser) scanNumberText(end int, expected string) (string, error) {
+	if end == x.position {
+		return "", x.Unexpected(expected, 10)
+	}
+	if err := x.checkTokenLength(end - x.position); err != nil {
+		return "", err
+	}
+	// numbers are ASCII only, so the byte length of the result equals its rune length
+	return x.slice(x.position, end), nil
+}
+
+// numberError converts an error from strconv to a ParseError.
+func (x *Parser) numberError(expected, text string, err error) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return x.NewParseError(NumberOverflow, expected, text, err)
+	}
+	return x.NewParseError(UnexpectedInput, expected, text, err)
+}
+
+// scanSign returns the index after an optional sign at index start.
+func (x *Parser) scanSign(start int, allowMinus bool) int {
+	if start < x.length() && (x.runeAt(start) == '+' || (allowMinus && x.runeAt(start) == '-')) {
+		return start + 1
+	}
+	return start
+}
+
+// scanInteger returns the index after the decimal integer starting at index start, or start if there is none.
+func (x *Parser) scanInteger(start int, allowMinus bool) int {
+	digitsStart := x.scanSign(start, allowMinus)
+	end := x.scanDecimalDigits(digitsStart)
+	if end == digitsStart {
+		return start
+	}
+	return end
+}
+
+// scanIntLiteral returns the index after the Go integer literal starting at index start, or start if there is none.
+func (x *Parser) scanIntLiteral(start int) int {
+	var (
+		pos         = x.scanSign(start, true)
+		digitsStart = pos
+		base        = 10
+	)
+	if pos+1 < x.length() && x.runeAt(pos) == '0' {
+		switch x.runeAt(pos + 1) {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+		if base != 10 {
+			pos += 2
+		}
+	}
+
+	// without a prefix, the first digit must not be an underscore
+	if base == 10 && (pos >= x.length() || digitValue(x.runeAt(pos)) >= 10) {
+		return start
+	}
+	end := x.scanDigits(pos, base, true)
+	switch {
+	case end > pos:
+		return end
+	case base != 10:
+		// a prefix without digits, just the zero is a number
+		return digitsStart + 1
+	}
+	return start
+}
+
+// scanFloat returns the index after the decimal floating point number starting at index start, or start if there is
+// none.
+func (x *Parser) scanFloat(start int) int {
+	pos := x.scanSign(start, true)
+
+	mantissaStart := pos
+	pos = x.scanDecimalDigits(pos)
+	digits := pos - mantissaStart
+	// the dot only belongs to the number if digits follow, so "1..2" or "x.1.y" are not misread
+	if pos < x.length() && x.runeAt(pos) == '.' {
+		if fractionEnd := x.scanDecimalDigits(pos + 1); fractionEnd > pos+1 {
+			digits += fractionEnd - pos - 1
+			pos = fractionEnd
+		}
+	}
+	if digits == 0 {
+		return start
+	}
+
+	// exponent, only if there are digits following
+	if pos < x.length() && (x.runeAt(pos) == 'e' || x.runeAt(pos) == 'E') {
+		expStart := x.scanSign(pos+1, true)
+		if expEnd := x.scanDecimalDigits(expStart); expEnd > expStart {
+			pos = expEnd
+		}
+	}
+	return pos
+}
+
+// scanInfNaN returns the index after "Inf", "Infinity" or "NaN" at index start, or start if there is none. The word
+// must not be followed by a letter, so "Info" is not taken for Inf.
+func (x *Parser) scanInfNaN(start int) int {
+	pos := x.scanSign(start, true)
+	for _, word := range []string{"infinity", "inf", "nan"} {
+		end := pos + len(word)
+		if end > x.length() || !strings.EqualFold(x.slice(pos, end), word) {
+			continue
+		}
+		if end < x.length() && unicode.IsLetter(x.runeAt(end)) {
+			continue
+		}
+		// signed NaN is not a thing
+		if word == "nan" && pos != start {
+			return start
+		}
+		return end
+	}
+	return start
+}
+
+// scanDecimalDigits returns the index after the ASCII digits starting at index start.
+func (x *Parser) scanDecimalDigits(start int) int {
+	pos := start
+	for pos < x.length() && x.runeAt(pos) >= '0' && x.runeAt(pos) <= '9' {
+		pos++
+	}
+	return pos
+}
+
+// scanDigits returns the index after the digits in base starting at index start, optionally allowing underscores.
+func (x *Parser) scanDigits(start, base int, underscores bool) int {
+	pos := start
+	for pos < x.length() {
+		r := x.runeAt(pos)
+		if !(underscores && r == '_') && digitValue(r) >= base {
+			break
+		}
+		pos++
+	}
+	return pos
+}
+
+// digitValue returns the value of r as a digit in bases up to 36, or 36 if it is none.
+func digitValue(r rune) int {
+	switch {
+	case r >= '0' && r <= '9':
+		return int(r - '0')
+	case r >= 'a' && r <= 'z':
+		return int(r-'a') + 10
+	case r >= 'A' && r <= 'Z':
+		return int(r-'A') + 10
+	}
+	return 36
+}
@@ -0,0 +1,238 @@
+package textparser
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParser_ReadFloat(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		want          float64
+		wantErr       bool
+		wantLookingAt string
+	}{
+		{"integer", "42", 42, false, ""},
+		{"negative", "-1.5 m", -1.5, false, " m"},
+		{"plus", "+2.25", 2.25, false, ""},
+		{"leading dot", ".5", 0.5, false, ""},
+		{"trailing dot", "3.", 3, false, "."},
+		{"range", "1..2", 1, false, "..2"},
+		{"member access", "1.x", 1, false, ".x"},
+		{"exponent", "6.02e23", 6.02e23, false, ""},
+		{"signed exponent", "1E-3x", 0.001, false, "x"},
+		{"no exponent digits", "2e", 2, false, "e"},
+		{"sentence end", "7. Next", 7, false, ". Next"},
+		{"sign only", "-x", 0, true, "-x"},
+		{"dot only", ".", 0, true, "."},
+		{"inf not requested", "Inf", 0, true, "Inf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(tt.input)
+			v, err := p.ReadFloat()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.want, v)
+			}
+			assert.Equal(t, tt.wantLookingAt, p.Remaining())
+		})
+	}
+}
+
+func TestParser_ReadFloatWithInfNaN(t *testing.T) {
+	a := assert.New(t)
+
+	v, err := NewParser("-Infinity").ReadFloatWithInfNaN()
+	a.Nil(err)
+	a.True(math.IsInf(v, -1))
+
+	v, err = NewParser("inf").ReadFloatWithInfNaN()
+	a.Nil(err)
+	a.True(math.IsInf(v, 1))
+
+	v, err = NewParser("NaN").ReadFloatWithInfNaN()
+	a.Nil(err)
+	a.True(math.IsNaN(v))
+
+	v, err = NewParser("1.5").ReadFloatWithInfNaN()
+	a.Nil(err)
+	a.Equal(1.5, v)
+
+	_, err = NewParser("-NaN").ReadFloatWithInfNaN()
+	a.Error(err)
+
+	// words starting like Inf or NaN are not numbers
+	for _, input := range []string{"Info", "nano", "-Infinite"} {
+		p := NewParser(input)
+		_, err = p.ReadFloatWithInfNaN()
+		a.Error(err, input)
+		a.Equal(0, p.CurrentIndex(), input)
+	}
+	p := NewParser("Inf, NaN")
+	a.True(math.IsInf(p.MustReadFloatWithInfNaN(), 1))
+	a.Equal(", NaN", p.Remaining())
+}
+
+func TestParser_ReadIntLoneSign(t *testing.T) {
+	readers := map[string]func(p *Parser) error{
+		"ReadInt": func(p *Parser) error {
+			_, err := p.ReadInt()
+			return err
+		},
+		"ReadInt64": func(p *Parser) error {
+			_, err := p.ReadInt64()
+			return err
+		},
+		"ReadUint64": func(p *Parser) error {
+			_, err := p.ReadUint64()
+			return err
+		},
+		"ReadIntLiteral": func(p *Parser) error {
+			_, err := p.ReadIntLiteral()
+			return err
+		},
+		"ReadIntBase": func(p *Parser) error {
+			_, err := p.ReadIntBase(16)
+			return err
+		},
+		"ReadBigInt": func(p *Parser) error {
+			_, err := p.ReadBigInt()
+			return err
+		},
+	}
+	for name, read := range readers {
+		for _, input := range []string{"+", "-", "+ 1", "-x", "+_1", "_1"} {
+			t.Run(name+" "+input, func(t *testing.T) {
+				p := NewParser(input)
+				err := read(p)
+
+				var parseErr *ParseError
+				assert.True(t, errors.As(err, &parseErr))
+				assert.Equal(t, UnexpectedInput, parseErr.Kind)
+				assert.Nil(t, parseErr.Err)
+				assert.Equal(t, 0, p.CurrentIndex())
+			})
+		}
+	}
+}
+
+func TestParser_ReadInt64(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("+9223372036854775807 -9223372036854775809")
+	a.Equal(int64(math.MaxInt64), p.MustReadInt64())
+	p.MustSkipSpaces()
+
+	_, err := p.ReadInt64()
+	var parseErr *ParseError
+	a.True(errors.As(err, &parseErr))
+	a.Equal(NumberOverflow, parseErr.Kind)
+	a.Equal("-9223372036854775809", parseErr.Found)
+	a.Equal(21, parseErr.Position.Offset)
+	a.Equal(21, p.CurrentIndex())
+}
+
+func TestParser_ReadUint64(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("18446744073709551615 -1")
+	a.Equal(uint64(math.MaxUint64), p.MustReadUint64())
+	p.MustSkipSpaces()
+
+	_, err := p.ReadUint64()
+	var parseErr *ParseError
+	a.True(errors.As(err, &parseErr))
+	a.Equal(UnexpectedInput, parseErr.Kind)
+	a.Equal("unsigned integer", parseErr.Expected)
+}
+
+func TestParser_ReadNumber(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("12 -3.5e2")
+	n := p.MustReadNumber()
+	a.True(n.IsInteger())
+	i, err := n.Int64()
+	a.Nil(err)
+	a.Equal(int64(12), i)
+
+	n = p.MustSkipSpaces().MustReadNumber()
+	a.False(n.IsInteger())
+	a.Equal("-3.5e2", n.String())
+	f, err := n.Float64()
+	a.Nil(err)
+	a.Equal(-350.0, f)
+}
+
+func TestParser_ReadIntLiteral(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		want          int64
+		wantKind      ErrorKind
+		wantLookingAt string
+	}{
+		{"decimal", "1_000_000", 1000000, 0, ""},
+		{"hex", "0x1F;", 31, 0, ";"},
+		{"hex upper", "-0XfF", -255, 0, ""},
+		{"octal", "0o755", 493, 0, ""},
+		{"legacy octal", "0755", 493, 0, ""},
+		{"binary", "0b1010 ", 10, 0, " "},
+		{"binary separated", "0b_1010_0101", 165, 0, ""},
+		{"zero", "0", 0, 0, ""},
+		{"prefix only", "0x", 0, 0, "x"},
+		{"stops at invalid digit", "0b102", 2, 0, "2"},
+		{"bad separator", "1__0", 0, UnexpectedInput, "1__0"},
+		{"overflow", "0x8000000000000000", 0, NumberOverflow, "0x8000000000000000"},
+		{"none", "x", 0, UnexpectedInput, "x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(tt.input)
+			v, err := p.ReadIntLiteral()
+			if tt.wantKind != 0 {
+				var parseErr *ParseError
+				if assert.True(t, errors.As(err, &parseErr)) {
+					assert.Equal(t, tt.wantKind, parseErr.Kind)
+				}
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.want, v)
+			}
+			assert.Equal(t, tt.wantLookingAt, p.Remaining())
+		})
+	}
+}
+
+func TestParser_ReadIntBase(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("ff zz -101 12")
+	a.Equal(int64(255), p.MustReadIntBase(16))
+	a.Equal(int64(35*36+35), p.MustSkipSpaces().MustReadIntBase(36))
+	a.Equal(int64(-5), p.MustSkipSpaces().MustReadIntBase(2))
+	a.Equal(int64(12), p.MustSkipSpaces().MustReadIntBase(0))
+
+	_, err := NewParser("2").ReadIntBase(2)
+	a.ErrorContains(err, "expected base 2 integer")
+	_, err = NewParser("1").ReadIntBase(37)
+	a.ErrorContains(err, "invalid base 37")
+}
+
+func TestParser_ReadBigInt(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("0x1_0000_0000_0000_0000 -123456789012345678901234567890")
+	a.Equal("18446744073709551616", p.MustReadBigInt().String())
+	a.Equal("-123456789012345678901234567890", p.MustSkipSpaces().MustReadBigInt().String())
+	a.True(p.IsExhausted())
+}
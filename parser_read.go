@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
-	"strings"
 	"unicode/utf8"
 )
 
@@ -12,6 +11,14 @@ func (x *Parser) MustReadRune() rune {
 	return x.MustReadRunes(1)[0]
 }
 
+func (x *Parser) ReadRune() (rune, error) {
+	runes, err := x.ReadRunes(1)
+	if err != nil {
+		return 0, err
+	}
+	return runes[0], nil
+}
+
 func (x *Parser) MustReadRunes(runeCount int) []rune {
 	value, err := x.ReadRunes(runeCount)
 	if err != nil {
@@ -20,17 +27,16 @@ func (x *Parser) MustReadRunes(runeCount int) []rune {
 	return value
 }
 
-func (x *Parser) ReadRune() (rune, error) {
-	runes, err := x.ReadRunes(1)
-	return runes[0], err
-}
-
 func (x *Parser) ReadRunes(runeCount int) ([]rune, error) {
 	if x.RemainingRuneCount() < runeCount {
 		var empty []rune
-		return empty, fmt.Errorf("could not read %d runes, reader is exhausted", runeCount)
+		return empty, x.NewParseError(EndOfInput, fmt.Sprintf("%d runes", runeCount), "", nil)
 	}
-	value := x.input[x.position : x.position+runeCount]
+	if err := x.checkTokenLength(runeCount); err != nil {
+		var empty []rune
+		return empty, err
+	}
+	value := []rune(x.slice(x.position, x.position+runeCount))
 	err := x.Skip(runeCount)
 	if err != nil {
 		var empty []rune
@@ -49,13 +55,14 @@ func (x *Parser) MustReadToRune(stopRune rune) string {
 
 func (x *Parser) ReadToRune(stopRune rune) (string, error) {
 	if x.IsExhausted() {
-		return "", fmt.Errorf("could not read on, reader is exhausted")
+		return "", x.NewParseError(EndOfInput, strconv.QuoteRune(stopRune), "", nil)
 	}
 	pos := x.position + 1
 	for {
-		// TODO check input limit
-		value := x.input[pos]
-		if value == stopRune {
+		if pos >= x.length() {
+			return "", x.NewParseErrorAt(x.length(), EndOfInput, strconv.QuoteRune(stopRune), "", nil)
+		}
+		if x.runeAt(pos) == stopRune {
 			break
 		}
 		pos++
@@ -74,14 +81,14 @@ func (x *Parser) MustReadWord() string {
 // ReadWord reads exactly one word of input. Stops at space, newline and end of input. Error if the input end is already reached.
 func (x *Parser) ReadWord() (string, error) {
 	if x.IsExhausted() {
-		return "", fmt.Errorf("could not read on, reader is exhausted")
+		return "", x.NewParseError(EndOfInput, "word", "", nil)
 	}
 	pos := x.position + 1
 	for {
-		if len(x.input) <= pos {
+		if x.length() <= pos {
 			break
 		}
-		value := x.input[pos]
+		value := x.runeAt(pos)
 		if value == ' ' {
 			break
 		}
@@ -93,14 +100,67 @@ func (x *Parser) ReadWord() (string, error) {
 	return x.readRunes(pos - x.position)
 }
 
+// ReadWhile reads runes as long as they satisfy pred. The result may be empty.
+func (x *Parser) ReadWhile(pred func(rune) bool) string {
+	end := x.scanWhile(x.position, -1, pred)
+	content := x.slice(x.position, end)
+	x.position = end
+	return content
+}
+
+// ReadUntil reads runes up to the first one satisfying pred, or to the end of input. The result may be empty.
+func (x *Parser) ReadUntil(pred func(rune) bool) string {
+	return x.ReadWhile(func(r rune) bool {
+		return !pred(r)
+	})
+}
+
+// ReadWhileN reads at least minCount and at most maxCount runes satisfying pred. A negative maxCount means no upper
+// limit.
+func (x *Parser) ReadWhileN(minCount, maxCount int, pred func(rune) bool) (string, error) {
+	end := x.scanWhile(x.position, maxCount, pred)
+	if end-x.position < minCount {
+		return "", x.Unexpected(fmt.Sprintf("at least %d matching runes", minCount), minCount)
+	}
+	if err := x.checkTokenLength(end - x.position); err != nil {
+		return "", err
+	}
+	content := x.slice(x.position, end)
+	x.position = end
+	return content, nil
+}
+
+func (x *Parser) MustReadWhileN(minCount, maxCount int, pred func(rune) bool) string {
+	value, err := x.ReadWhileN(minCount, maxCount, pred)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// scanWhile returns the index after the runes satisfying pred starting at index start, reading at most maxCount
+// runes unless it is negative.
+func (x *Parser) scanWhile(start, maxCount int, pred func(rune) bool) int {
+	pos := start
+	for pos < x.length() && (maxCount < 0 || pos-start < maxCount) && pred(x.runeAt(pos)) {
+		pos++
+	}
+	return pos
+}
+
 func (x *Parser) readRunes(runeCount int) (string, error) {
-	// TODO check length
-	value := x.input[x.position : x.position+runeCount]
-	err := x.Skip(len(value))
+	if runeCount < 0 || x.RemainingRuneCount() < runeCount {
+		return "", x.NewParseError(EndOfInput, fmt.Sprintf("%d runes", runeCount), "", nil)
+	}
+	if err := x.checkTokenLength(runeCount); err != nil {
+		return "", err
+	}
+	value := x.slice(x.position, x.position+runeCount)
+	err := x.Skip(runeCount)
 	if err != nil {
 		return "", err
 	}
-	return string(value), nil
+	return value, nil
 }
 
 func (x *Parser) ReadRestOfLine() (string, error) {
@@ -108,7 +168,7 @@ func (x *Parser) ReadRestOfLine() (string, error) {
 
 	// end of input?
 	if errors.Is(err, EndOfInputError{}) {
-		value, err := x.getToIndex(len(x.input))
+		value, err := x.getToIndex(x.length())
 		if err != nil {
 			return "", err
 		}
@@ -148,7 +208,7 @@ func (x *Parser) MustReadRestOfLine() string {
 }
 
 func (x *Parser) ReadRestOfInput() (string, error) {
-	return x.ReadToPositionString(len(x.input))
+	return x.ReadToPositionString(x.length())
 }
 
 func (x *Parser) MustReadRestOfInput() string {
@@ -159,45 +219,17 @@ func (x *Parser) MustReadRestOfInput() string {
 	return result
 }
 
+// ReadInt reads a decimal integer with an optional sign.
 func (x *Parser) ReadInt() (int, error) {
-	var (
-		num    strings.Builder
-		numPos = x.position
-		r      rune
-	)
-
-	for {
-		// exhausted?
-		if numPos >= len(x.input) {
-			break
-		}
-
-		r = x.input[numPos]
-
-		if r != '0' && r != '1' && r != '2' && r != '3' && r != '4' && r != '5' && r != '6' && r != '7' && r != '8' && r != '9' && (numPos > x.position || r != '-') {
-			break
-		}
-
-		num.WriteRune(r)
-		numPos++
-	}
-
-	if num.Len() == 0 {
-		return 0, fmt.Errorf(
-			"expected to find an integer at position %d, but looking at '%s'",
-			x.position,
-			x.GetNextMax(10),
-		)
-	}
-
-	// try to convert (should only fail due to the controlled buildup above when the number is too big)
-	v, err := strconv.Atoi(num.String())
+	text, err := x.scanNumberText(x.scanInteger(x.position, true), "integer")
 	if err != nil {
 		return 0, err
 	}
-
-	_ = x.Skip(num.Len())
-
+	v, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, x.numberError("integer", text, err)
+	}
+	x.MustSkip(len(text))
 	return v, nil
 }
 
@@ -210,12 +242,15 @@ func (x *Parser) MustReadInt() int {
 }
 
 func (x *Parser) ReadToPositionString(newPosition int) (string, error) {
-	if newPosition > len(x.input) {
-		return "", EndOfInputError{}
+	if newPosition > x.length() {
+		return "", x.NewParseError(EndOfInput, fmt.Sprintf("%d more runes", newPosition-x.position), "", nil)
 	}
-	content := x.input[x.position:newPosition]
+	if err := x.checkTokenLength(newPosition - x.position); err != nil {
+		return "", err
+	}
+	content := x.slice(x.position, newPosition)
 	x.position = newPosition
-	return string(content), nil
+	return content, nil
 }
 
 func (x *Parser) MustReadToPositionString(newPosition int) string {
@@ -226,16 +261,12 @@ func (x *Parser) MustReadToPositionString(newPosition int) string {
 	return value
 }
 
+// ReadToAnyString reads up to the first occurrence of any of limitStrings, which is not consumed. The strings are
+// searched for one by one. To search for many strings or for the same strings repeatedly, compile them using
+// NewStopSet and use ReadToStopSet.
 func (x *Parser) ReadToAnyString(limitStrings []string) (string, error) {
-	newPos, err := x.findAnyNext(limitStrings)
-	if err != nil {
-		return "", err
-	}
-	content, err := x.ReadToPositionString(newPos)
-	if err != nil {
-		return "", err
-	}
-	return content, nil
+	content, _, err := x.ReadToStopSet(patternStops(limitStrings))
+	return content, err
 }
 
 func (x *Parser) MustReadToAnyString(limitStrings []string) string {
@@ -246,25 +277,113 @@ func (x *Parser) MustReadToAnyString(limitStrings []string) string {
 	return content
 }
 
+// ReadToAnyStringOrEnd is like ReadToAnyString, but reads the rest of the input if none of limitStrings occurs.
 func (x *Parser) ReadToAnyStringOrEnd(limitStrings []string) (string, error) {
-	newPos, err := x.findAnyNext(limitStrings)
+	content, _, err := x.ReadToStopSetOrEnd(patternStops(limitStrings))
+	return content, err
+}
+
+func (x *Parser) MustReadToAnyStringOrEnd(limitStrings []string) string {
+	content, err := x.ReadToAnyStringOrEnd(limitStrings)
 	if err != nil {
-		if errors.Is(err, EndOfInputError{}) {
-			return x.ReadRestOfInput()
-		}
-		return "", err
+		panic(err)
 	}
-	content, err := x.ReadToPositionString(newPos)
+	return content
+}
+
+// DelimiterMode determines what happens to the delimiter found by ReadToAnyStringMatch.
+type DelimiterMode int
+
+const (
+	// LeaveDelimiter stops in front of the delimiter.
+	LeaveDelimiter DelimiterMode = iota
+	// ConsumeDelimiter skips the delimiter, it is not part of the content.
+	ConsumeDelimiter
+)
+
+// AnyStringMatch is the result of ReadToAnyStringMatch.
+type AnyStringMatch struct {
+	// Content is the text read up to the delimiter.
+	Content string
+	// Index is the index of the delimiter in the limit strings, -1 if the end of input was reached.
+	Index int
+	// Delimiter is the delimiter found, empty if the end of input was reached.
+	Delimiter string
+}
+
+// ReadToAnyStringMatch reads up to the first occurrence of any of limitStrings like ReadToAnyString, and reports
+// which one was found. Depending on mode, the delimiter is consumed or left. See ReadToStopSetMatch for reusing the
+// strings.
+func (x *Parser) ReadToAnyStringMatch(limitStrings []string, mode DelimiterMode) (AnyStringMatch, error) {
+	return x.ReadToStopSetMatch(patternStops(limitStrings), mode)
+}
+
+func (x *Parser) MustReadToAnyStringMatch(limitStrings []string, mode DelimiterMode) AnyStringMatch {
+	match, err := x.ReadToAnyStringMatch(limitStrings, mode)
 	if err != nil {
-		return "", err
+		panic(err)
 	}
-	return content, nil
+	return match
 }
 
-func (x *Parser) MustReadToAnyStringOrEnd(limitStrings []string) string {
-	content, err := x.ReadToAnyStringOrEnd(limitStrings)
+// ReadToAnyStringOrEndMatch is like ReadToAnyStringMatch, but reads the rest of the input if none of limitStrings
+// occurs. The Index of the result is -1 in that case.
+func (x *Parser) ReadToAnyStringOrEndMatch(limitStrings []string, mode DelimiterMode) (AnyStringMatch, error) {
+	return x.ReadToStopSetOrEndMatch(patternStops(limitStrings), mode)
+}
+
+func (x *Parser) MustReadToAnyStringOrEndMatch(limitStrings []string, mode DelimiterMode) AnyStringMatch {
+	match, err := x.ReadToAnyStringOrEndMatch(limitStrings, mode)
 	if err != nil {
 		panic(err)
 	}
-	return content
+	return match
+}
+
+// ReadToStopSetMatch is like ReadToAnyStringMatch, but searches for the patterns of a precompiled StopSet.
+func (x *Parser) ReadToStopSetMatch(set *StopSet, mode DelimiterMode) (AnyStringMatch, error) {
+	content, index, err := x.ReadToStopSet(set)
+	if err != nil {
+		return AnyStringMatch{Index: -1}, err
+	}
+	return x.delimiterMatch(content, set, index, mode), nil
+}
+
+func (x *Parser) MustReadToStopSetMatch(set *StopSet, mode DelimiterMode) AnyStringMatch {
+	match, err := x.ReadToStopSetMatch(set, mode)
+	if err != nil {
+		panic(err)
+	}
+	return match
+}
+
+// ReadToStopSetOrEndMatch is like ReadToAnyStringOrEndMatch, but searches for the patterns of a precompiled StopSet.
+func (x *Parser) ReadToStopSetOrEndMatch(set *StopSet, mode DelimiterMode) (AnyStringMatch, error) {
+	content, index, err := x.ReadToStopSetOrEnd(set)
+	if err != nil {
+		return AnyStringMatch{Index: -1}, err
+	}
+	return x.delimiterMatch(content, set, index, mode), nil
+}
+
+func (x *Parser) MustReadToStopSetOrEndMatch(set *StopSet, mode DelimiterMode) AnyStringMatch {
+	match, err := x.ReadToStopSetOrEndMatch(set, mode)
+	if err != nil {
+		panic(err)
+	}
+	return match
+}
+
+// delimiterMatch builds the result for the pattern of set with the given index at the current position, skipping it
+// depending on mode.
+func (x *Parser) delimiterMatch(content string, set *StopSet, index int, mode DelimiterMode) AnyStringMatch {
+	match := AnyStringMatch{Content: content, Index: index}
+	if index < 0 {
+		return match
+	}
+	match.Delimiter = set.patterns[index]
+	if mode == ConsumeDelimiter {
+		x.position += utf8.RuneCountInString(match.Delimiter)
+	}
+	return match
 }
@@ -0,0 +1,141 @@
+package textparser
+
+import (
+	"io"
+	"regexp"
+	"unicode/utf8"
+)
+
+// Submatch is a (sub)match of a regular expression, see ReadRegexpSubmatch.
+type Submatch struct {
+	Text string
+	// Start and End are the rune indices of the match, both are -1 for groups that did not participate in the match.
+	Start int
+	End   int
+}
+
+// inputReader is an io.RuneReader on the input from a byte offset on. It does not copy the input and does not move
+// the parser.
+type inputReader struct {
+	parser *Parser
+	offset int
+}
+
+func (x *inputReader) ReadRune() (rune, int, error) {
+	if x.offset >= len(x.parser.input) {
+		return 0, 0, io.EOF
+	}
+	r, size := utf8.DecodeRuneInString(x.parser.input[x.offset:])
+	x.offset += size
+	return r, size, nil
+}
+
+// LookingAtRegexp determines if re matches at the current position. re is used as it is, including its POSIX and
+// Longest semantics, and does not need to be anchored: the parser compiles an anchored copy once per regexp, which
+// rules out a match without reading beyond it.
+func (x *Parser) LookingAtRegexp(re *regexp.Regexp) bool {
+	return x.findRegexp(re) != nil
+}
+
+// ReadRegexp reads the match of re at the current position. The match may be empty.
+func (x *Parser) ReadRegexp(re *regexp.Regexp) (string, error) {
+	submatches, err := x.ReadRegexpSubmatch(re)
+	if err != nil {
+		return "", err
+	}
+	return submatches[0].Text, nil
+}
+
+func (x *Parser) MustReadRegexp(re *regexp.Regexp) string {
+	value, err := x.ReadRegexp(re)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// ReadRegexpSubmatch reads the match of re at the current position and returns it along with its submatches, like
+// regexp.Regexp.FindStringSubmatch does. The parser only advances if re matches. See LookingAtRegexp on anchoring.
+func (x *Parser) ReadRegexpSubmatch(re *regexp.Regexp) ([]Submatch, error) {
+	byteOffsets := x.findRegexp(re)
+	if byteOffsets == nil {
+		return nil, x.Unexpected("match of /"+re.String()+"/", 10)
+	}
+
+	var (
+		submatches = make([]Submatch, len(byteOffsets)/2)
+		indices    = x.byteOffsetsToIndices(byteOffsets)
+	)
+	if err := x.checkTokenLength(indices[1] - indices[0]); err != nil {
+		return nil, err
+	}
+	for i := range submatches {
+		start, end := indices[2*i], indices[2*i+1]
+		submatches[i] = Submatch{Start: start, End: end}
+		if start >= 0 {
+			submatches[i].Text = x.slice(start, end)
+		}
+	}
+
+	x.position = submatches[0].End
+	return submatches, nil
+}
+
+func (x *Parser) MustReadRegexpSubmatch(re *regexp.Regexp) []Submatch {
+	value, err := x.ReadRegexpSubmatch(re)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// byteOffsetsToIndices converts byte offsets relative to the current position to rune indices. Negative offsets
+// are kept as -1.
+func (x *Parser) byteOffsetsToIndices(byteOffsets []int) []int {
+	var (
+		base    = x.byteOffset(x.position)
+		indices = make([]int, len(byteOffsets))
+	)
+	for i, offset := range byteOffsets {
+		indices[i] = -1
+		if offset >= 0 {
+			indices[i] = x.position + utf8.RuneCountInString(x.input[base:base+offset])
+		}
+	}
+	return indices
+}
+
+// findRegexp returns the byte offsets of the match of re and its submatches relative to the current position, or nil
+// if re does not match there.
+func (x *Parser) findRegexp(re *regexp.Regexp) []int {
+	offset := x.byteOffset(x.position)
+	// re alone would search the rest of the input for a match if there is none here
+	if anchored := x.anchoredRegexp(re); anchored != nil && !anchored.MatchReader(&inputReader{parser: x, offset: offset}) {
+		return nil
+	}
+	byteOffsets := re.FindReaderSubmatchIndex(&inputReader{parser: x, offset: offset})
+	// the leftmost match starts at the current position if there is a match there at all
+	if byteOffsets == nil || byteOffsets[0] != 0 {
+		return nil
+	}
+	return byteOffsets
+}
+
+// anchoredRegexp returns a copy of re anchored at the start of the input, compiled once per parser, or nil if re can't
+// be anchored. It matches wherever re does, but maybe more often: ^ and $ within it also match at line boundaries, as
+// they do in regexps compiled with POSIX syntax. So it only tells where re can't match.
+func (x *Parser) anchoredRegexp(re *regexp.Regexp) *regexp.Regexp {
+	anchored, ok := x.anchoredRegexps[re]
+	if ok {
+		return anchored
+	}
+	anchored, err := regexp.Compile(`^(?:(?m:` + re.String() + `))`)
+	if err != nil {
+		anchored = nil
+	}
+	if x.anchoredRegexps == nil {
+		x.anchoredRegexps = make(map[*regexp.Regexp]*regexp.Regexp)
+	}
+	x.anchoredRegexps[re] = anchored
+	return anchored
+}
@@ -0,0 +1,109 @@
+package textparser
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParser_ReadRegexp(t *testing.T) {
+	a := assert.New(t)
+
+	date := regexp.MustCompile(`(\d{4})-(\d{2})-(\d{2})`)
+	p := NewParser("on 2024-01-15: Ärger 2025-02-01")
+
+	// no match at the cursor, even though there is one later on
+	a.False(p.LookingAtRegexp(date))
+	_, err := p.ReadRegexp(date)
+	a.ErrorContains(err, `expected match of /(\d{4})-(\d{2})-(\d{2})/, found "on 2024-01"`)
+	a.Equal(0, p.CurrentIndex())
+
+	p.MustSkipString("on ")
+	a.True(p.LookingAtRegexp(date))
+	a.Equal("2024-01-15", p.MustReadRegexp(date))
+	a.True(p.LookingAtString(": "))
+
+	// empty matches succeed
+	a.Equal("", p.MustReadRegexp(regexp.MustCompile(`x*`)))
+	a.Equal(13, p.CurrentIndex())
+
+	// anchored expressions work the same
+	p.MustSkipString(": ")
+	a.Equal("Ärger", p.MustReadRegexp(regexp.MustCompile(`^\pL+`)))
+}
+
+func TestParser_ReadRegexpLongest(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("a", NewParser("abc").MustReadRegexp(regexp.MustCompile(`a|ab`)))
+	a.Equal("ab", NewParser("abc").MustReadRegexp(regexp.MustCompilePOSIX(`a|ab`)))
+
+	longest := regexp.MustCompile(`a|ab`)
+	longest.Longest()
+	a.Equal("ab", NewParser("abc").MustReadRegexp(longest))
+}
+
+func TestParser_ReadRegexpAnchors(t *testing.T) {
+	a := assert.New(t)
+
+	input := "ab\nab"
+	for _, re := range []*regexp.Regexp{
+		regexp.MustCompile(`b$`),
+		regexp.MustCompile(`(?m)b$`),
+		regexp.MustCompilePOSIX(`b$`),
+		regexp.MustCompile(`^b`),
+		regexp.MustCompilePOSIX(`^b`),
+		regexp.MustCompile(`b\n^a`),
+		regexp.MustCompilePOSIX(`b\n^a`),
+		regexp.MustCompile(`[^x]+`),
+		regexp.MustCompilePOSIX(`[^x]+`),
+	} {
+		p := NewParser(input)
+		for i := 0; i < len(input); i++ {
+			loc := re.FindStringIndex(input[i:])
+			want := ""
+			if loc != nil && loc[0] == 0 {
+				want = input[i : i+loc[1]]
+			}
+			value, _ := p.ReadRegexp(re)
+			a.Equal(want, value, "%s at %d", re, i)
+			p.Reset(Mark{}).MustSkip(i + 1)
+		}
+	}
+}
+
+func TestParser_ReadRegexpSubmatch(t *testing.T) {
+	a := assert.New(t)
+
+	keyValue := regexp.MustCompile(`(?i)(\pL+)\s*=\s*(\d+)?(ö+)`)
+	p := NewParser("Größe = öö;")
+	submatches, err := p.ReadRegexpSubmatch(keyValue)
+	a.Nil(err)
+	a.Equal([]Submatch{
+		{Text: "Größe = öö", Start: 0, End: 10},
+		{Text: "Größe", Start: 0, End: 5},
+		{Text: "", Start: -1, End: -1},
+		{Text: "öö", Start: 8, End: 10},
+	}, submatches)
+	a.True(p.LookingAtString(";"))
+}
+
+func BenchmarkParser_LookingAtRegexp(b *testing.B) {
+	var (
+		comment = regexp.MustCompile(`//[^\n]*`)
+		p       = NewParser(strings.Repeat("x = 1\n", 3000) + "// end")
+	)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Reset(Mark{})
+		for p.HasMore() {
+			if !p.LookingAtRegexp(comment) {
+				p.MustSkip(1)
+				continue
+			}
+			p.MustReadRegexp(comment)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package textparser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ScanError is returned by Scanf if a verb could not be satisfied. The wrapped error is usually a *ParseError holding
+// the position.
+type ScanError struct {
+	// Verb is the failing verb including the percent sign, e.g. "%d".
+	Verb string
+	// Argument is the 1-based index of the argument the verb was meant to store into, 0 if none.
+	Argument int
+	Err      error
+}
+
+func (x *ScanError) Error() string {
+	if x.Argument == 0 {
+		return fmt.Sprintf("verb %s: %s", x.Verb, x.Err)
+	}
+	return fmt.Sprintf("verb %s (argument %d): %s", x.Verb, x.Argument, x.Err)
+}
+
+func (x *ScanError) Unwrap() error {
+	return x.Err
+}
+
+var scanfGroups = map[rune]rune{
+	'(': ')',
+	'[': ']',
+	'{': '}',
+	'<': '>',
+}
+
+// Scanf matches the input against format and stores the values of its verbs in args. Literal text in format must
+// match exactly, except for runs of spaces and tabs, which match any number of spaces and tabs (including none).
+// On failure, the parser is reset to where it started and none of args is changed.
+//
+// Supported verbs:
+//
+//	%d     integer, into *int or *int64
+//	%f     floating point number, into *float64 or *float32
+//	%s %w  word, up to the next space or newline, into *string
+//	%l     rest of the line, into *string
+//	%q     double quoted string with the escapes of Go, see strconv.Unquote, into *string (unquoted)
+//	%( %[ %{ %<  balanced group, into *string (content without the delimiters)
+//	%%     a literal percent sign
+//
+// A star after the percent sign (e.g. %*d) reads the value without storing it.
+func (x *Parser) Scanf(format string, args ...any) error {
+	return x.Attempt(func(p *Parser) error {
+		return p.scanf(format, args, false)
+	})
+}
+
+func (x *Parser) MustScanf(format string, args ...any) *Parser {
+	err := x.Scanf(format, args...)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+// Sscanf is a shortcut for using Scanf on input. The whole input must be consumed by format, otherwise none of args is
+// changed.
+func Sscanf(input string, format string, args ...any) error {
+	return NewParser(input).scanf(format, args, true)
+}
+
+// scanf implements Scanf, additionally requiring the end of input after format if atEnd is set.
+func (x *Parser) scanf(format string, args []any, atEnd bool) error {
+	var (
+		argIndex = 0
+		literal  strings.Builder
+		// assignments are applied once the whole format matched
+		assignments []func()
+	)
+
+	// matchLiteral matches the literal text collected so far.
+	matchLiteral := func() error {
+		defer literal.Reset()
+		for _, part := range splitSpaces(literal.String()) {
+			if strings.TrimLeft(part, " \t") == "" {
+				_ = x.SkipAny([]rune{' ', '\t'})
+				continue
+			}
+			err := x.SkipString(part)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i := 0; i < len(format); {
+		r, size := utf8.DecodeRuneInString(format[i:])
+		i += size
+		if r != '%' {
+			literal.WriteRune(r)
+			continue
+		}
+
+		verb, size := utf8.DecodeRuneInString(format[i:])
+		i += size
+		if verb == '%' {
+			literal.WriteRune('%')
+			continue
+		}
+		err := matchLiteral()
+		if err != nil {
+			return err
+		}
+
+		store := true
+		if verb == '*' {
+			store = false
+			verb, size = utf8.DecodeRuneInString(format[i:])
+			i += size
+		}
+		verbText := "%" + string(verb)
+		if !store {
+			verbText = "%*" + string(verb)
+		}
+
+		var arg any
+		if store {
+			if argIndex >= len(args) {
+				return &ScanError{Verb: verbText, Err: x.NewParseError(InvalidArgument, "", "", fmt.Errorf("missing argument"))}
+			}
+			arg = args[argIndex]
+			argIndex++
+		}
+
+		assign, err := x.scanVerb(verb, arg)
+		if err != nil {
+			scanErr := &ScanError{Verb: verbText, Err: err}
+			if store {
+				scanErr.Argument = argIndex
+			}
+			return scanErr
+		}
+		if assign != nil {
+			assignments = append(assignments, assign)
+		}
+	}
+
+	err := matchLiteral()
+	if err != nil {
+		return err
+	}
+	if argIndex < len(args) {
+		return x.NewParseError(InvalidArgument, "", "", fmt.Errorf("%d arguments given, but format uses %d", len(args), argIndex))
+	}
+	if atEnd && x.HasMore() {
+		return x.NewParseError(UnexpectedInput, "end of input", x.GetNextMax(10), nil)
+	}
+	for _, assign := range assignments {
+		assign()
+	}
+	return nil
+}
+
+// scanVerb reads a single verb and returns the function storing it in arg, nil if arg is nil.
+func (x *Parser) scanVerb(verb rune, arg any) (func(), error) {
+	var (
+		value any
+		err   error
+	)
+	switch verb {
+	case 'd':
+		value, err = x.ReadInt()
+	case 'f':
+		value, err = x.ReadFloat()
+	case 's', 'w':
+		value, err = x.ReadWord()
+	case 'l':
+		value, err = x.ReadRestOfLine()
+	case 'q':
+		value, err = x.readQuoted()
+	default:
+		closing, ok := scanfGroups[verb]
+		if !ok {
+			return nil, x.NewParseError(InvalidArgument, "", "", fmt.Errorf("unknown verb %%%c", verb))
+		}
+		value, err = x.ReadToMatchingRuneSkipDelims(verb, closing)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if arg == nil {
+		return nil, nil
+	}
+
+	switch target := arg.(type) {
+	case *int:
+		if v, ok := value.(int); ok {
+			return func() { *target = v }, nil
+		}
+	case *int64:
+		if v, ok := value.(int); ok {
+			return func() { *target = int64(v) }, nil
+		}
+	case *float64:
+		if v, ok := value.(float64); ok {
+			return func() { *target = v }, nil
+		}
+	case *float32:
+		if v, ok := value.(float64); ok {
+			return func() { *target = float32(v) }, nil
+		}
+	case *string:
+		if v, ok := value.(string); ok {
+			return func() { *target = v }, nil
+		}
+	}
+	return nil, x.NewParseError(InvalidArgument, "", "", fmt.Errorf("can't store %T in %T", value, arg))
+}
+
+// readQuoted reads a double quoted string with the escapes of Go and returns its content, see strconv.Unquote.
+func (x *Parser) readQuoted() (string, error) {
+	if !x.LookingAtRune('"') {
+		return "", x.Unexpected("opening "+strconv.QuoteRune('"'), 1)
+	}
+
+	// find the closing quote, a backslash escapes any rune
+	end := x.position + 1
+	for end < x.length() && x.runeAt(end) != '"' {
+		if x.runeAt(end) == '\\' {
+			end++
+		}
+		end++
+	}
+	if end >= x.length() {
+		return "", x.NewParseErrorAt(x.length(), EndOfInput, "closing "+strconv.QuoteRune('"'), "", nil)
+	}
+	end++
+
+	if err := x.checkTokenLength(end - x.position); err != nil {
+		return "", err
+	}
+	text := x.slice(x.position, end)
+	value, err := strconv.Unquote(text)
+	if err != nil {
+		return "", x.NewParseError(UnexpectedInput, "quoted string", text, err)
+	}
+	x.position = end
+	return value, nil
+}
+
+// splitSpaces splits s into runs of spaces and tabs and the text in between.
+func splitSpaces(s string) []string {
+	var (
+		parts   []string
+		start   = 0
+		inSpace = false
+	)
+	for i, r := range s {
+		isSpace := r == ' ' || r == '\t'
+		if i > start && isSpace != inSpace {
+			parts = append(parts, s[start:i])
+			start = i
+		}
+		inSpace = isSpace
+	}
+	if start < len(s) {
+		parts = append(parts, s[start:])
+	}
+	return parts
+}
@@ -0,0 +1,109 @@
+package textparser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParser_Scanf(t *testing.T) {
+	a := assert.New(t)
+
+	var (
+		id     int
+		name   string
+		weight float64
+		note   string
+		tags   string
+		rest   string
+	)
+	p := NewParser("Game 22: Tom  -1.5e2 \"a \\\" quote\" [x, [y]] 100%\nnext")
+	err := p.Scanf("Game %d: %s %f %q %[ %*d%% %l", &id, &name, &weight, &note, &tags, &rest)
+	a.Nil(err)
+	a.Equal(22, id)
+	a.Equal("Tom", name)
+	a.Equal(-150.0, weight)
+	a.Equal(`a " quote`, note)
+	a.Equal("x, [y]", tags)
+	a.Equal("", rest)
+	a.True(p.LookingAtString("\nnext"), p.CurrentContext())
+}
+
+func TestParser_ScanfError(t *testing.T) {
+	a := assert.New(t)
+
+	var (
+		id   int
+		name string
+	)
+	p := NewParser("Game x: Tom")
+	err := p.Scanf("Game %d: %s", &id, &name)
+
+	var scanErr *ScanError
+	a.True(errors.As(err, &scanErr))
+	a.Equal("%d", scanErr.Verb)
+	a.Equal(1, scanErr.Argument)
+
+	var parseErr *ParseError
+	a.True(errors.As(err, &parseErr))
+	a.Equal(5, parseErr.Position.Offset)
+	a.Equal(0, p.CurrentIndex())
+	a.EqualError(err, `verb %d (argument 1): unexpected input at line 1, column 6, expected integer, found "x: Tom"`)
+
+	err = NewParser("Game 1").Scanf("Game %d", &name)
+	a.ErrorContains(err, "can't store int in *string")
+	err = p.Scanf("Round %d", &id)
+	a.ErrorContains(err, `expected "Round"`)
+
+	// earlier verbs are not stored if a later one fails
+	err = NewParser("Game 7: ").Scanf("Game %d: %s", &id, &name)
+	a.Error(err)
+	a.Equal(0, id)
+	a.Equal("", name)
+}
+
+func TestSscanf(t *testing.T) {
+	a := assert.New(t)
+
+	var (
+		x, y int
+	)
+	a.Nil(Sscanf("move 3,4", "move %d,%d", &x, &y))
+	a.Equal(3, x)
+	a.Equal(4, y)
+
+	a.ErrorContains(Sscanf("move 5,6!", "move %d,%d", &x, &y), "expected end of input")
+	a.ErrorContains(Sscanf("move 5,6", "move %d,%d %d", &x, &y), "missing argument")
+	a.Equal(3, x, "arguments are unchanged on failure")
+	a.Equal(4, y, "arguments are unchanged on failure")
+}
+
+func TestSscanf_quoted(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr string
+	}{
+		{input: `"a\\" b`, want: `a\`},
+		{input: `"say \"hi\"" b`, want: `say "hi"`},
+		{input: `"tab\tä\u00e4\x41" b`, want: "tab\tääA"},
+		{input: `"" b`, want: ""},
+		{input: `"\q" b`, wantErr: `unexpected input at line 1, column 1, expected quoted string, found "\"\\q\"": invalid syntax`},
+		{input: `"open b`, wantErr: `unexpected end of input at line 1, column 8, expected closing '"'`},
+		{input: `"a\" b`, wantErr: `unexpected end of input at line 1, column 7, expected closing '"'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			value := "unchanged"
+			err := Sscanf(tt.input, "%q b", &value)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Equal(t, "unchanged", value)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, value)
+		})
+	}
+}
@@ -3,18 +3,15 @@ package textparser
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"unicode/utf8"
 )
 
 // Skip skips runeCount runes without returning them.
 func (x *Parser) Skip(runeCount int) error {
-	if x.position+runeCount > len(x.input) {
-		return fmt.Errorf(
-			"can't advance another %d runes, the input contains %d runes and current pointer is at %d",
-			runeCount,
-			len(x.input),
-			x.position,
-		)
+	if x.position+runeCount > x.length() {
+		return x.NewParseError(EndOfInput, fmt.Sprintf("%d more runes", runeCount), "", nil)
 	}
 	x.position += runeCount
 	return nil
@@ -32,19 +29,14 @@ func (x *Parser) MustSkip(runeCount int) *Parser {
 // SkipTo skips to index newIndex without returning the intermediate runes (forward only).
 func (x *Parser) SkipTo(newIndex int) error {
 	if x.position > newIndex {
-		return fmt.Errorf("can't skip backwards, current %d, target position %d", x.position, newIndex)
+		return x.NewParseError(InvalidArgument, "", "", fmt.Errorf("can't skip backwards, current %d, target position %d", x.position, newIndex))
 	}
 	return x.Skip(newIndex - x.position)
 }
 
 func (x *Parser) SkipString(expected string) error {
 	if !x.LookingAtString(expected) {
-		return fmt.Errorf(
-			"expected string '%s' at position %d, but looking at '%s' instead",
-			expected,
-			x.position,
-			x.GetNextMax(utf8.RuneCountInString(expected)),
-		)
+		return x.Unexpected(strconv.Quote(expected), utf8.RuneCountInString(expected))
 	}
 	return x.Skip(utf8.RuneCountInString(expected))
 }
@@ -77,7 +69,7 @@ func (x *Parser) SkipToString(expected string) error {
 	var err error
 	for {
 		if x.IsExhausted() {
-			return EndOfInputError{}
+			return x.NewParseError(EndOfInput, strconv.Quote(expected), "", nil)
 		}
 
 		if x.LookingAtString(expected) {
@@ -132,31 +124,25 @@ func (x *Parser) SkipAnyWhitespaces() error {
 	return x.SkipAny([]rune{'\n', '\t', ' '})
 }
 
+// SkipAny skips all runes contained in runes. See SkipSet for skipping a precompiled RuneSet.
 func (x *Parser) SkipAny(runes []rune) error {
-	var (
-		goOn bool
-		err  error
-	)
-	for {
-		goOn = false
-		for _, r := range runes {
-			if x.LookingAtRune(r) {
-				goOn = true
-				break
+	x.SkipWhile(func(r rune) bool {
+		for _, candidate := range runes {
+			if r == candidate {
+				return true
 			}
 		}
-		if !goOn {
-			break
-		}
-
-		err = x.Skip(1)
-		if err != nil {
-			return err
-		}
-	}
+		return false
+	})
 	return nil
 }
 
+// SkipWhile skips runes as long as they satisfy pred (chainable).
+func (x *Parser) SkipWhile(pred func(rune) bool) *Parser {
+	x.position = x.scanWhile(x.position, -1, pred)
+	return x
+}
+
 func (x *Parser) MustSkipSpaces() *Parser {
 	err := x.SkipSpaces()
 	if err != nil {
@@ -191,7 +177,7 @@ func (x *Parser) SkipRestOfLine() error {
 }
 
 func (x *Parser) SkipToEnd() *Parser {
-	x.position = len(x.input)
+	x.position = x.length()
 	return x
 }
 
@@ -209,32 +195,38 @@ func (x *Parser) MustSkipNewlines() *Parser {
 
 func (x *Parser) getToIndex(endIndex int) (string, error) {
 	if x.position > endIndex {
-		return "", fmt.Errorf("can't read backwards, current %d, target position %d", x.position, endIndex)
+		return "", x.NewParseError(InvalidArgument, "", "", fmt.Errorf("can't read backwards, current %d, target position %d", x.position, endIndex))
+	}
+	if endIndex > x.length() {
+		return "", x.NewParseError(InvalidArgument, "", "", fmt.Errorf("can't read that far, current index %d, target position %d, input length %d", x.position, endIndex, x.length()))
 	}
-	if endIndex > len(x.input) {
-		return "", fmt.Errorf("can't read that far, current index %d, target position %d, input length %d", x.position, endIndex, len(x.input))
+	if err := x.checkTokenLength(endIndex - x.position); err != nil {
+		return "", err
 	}
 
 	return x.MustGetNext(endIndex - x.position), nil
 }
 
-func (x *Parser) findNext(r string) (int, error) {
-	return x.findAnyNext([]string{r})
+// SkipToAnyString skips up to the first occurrence of any of limitStrings, which is not consumed, and returns the
+// index of the string found. See ReadToAnyString on reusing the strings.
+func (x *Parser) SkipToAnyString(limitStrings []string) (int, error) {
+	return x.SkipToStopSet(patternStops(limitStrings))
 }
 
-func (x *Parser) findAnyNext(r []string) (int, error) {
-	pos := x.position
+func (x *Parser) MustSkipToAnyString(limitStrings []string) *Parser {
+	_, err := x.SkipToAnyString(limitStrings)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
 
-	for {
-		// exhausted?
-		if pos >= len(x.input) {
-			return 0, EndOfInputError{}
-		}
-		for _, s := range r {
-			if string(x.input[pos:pos+len(s)]) == s {
-				return pos, nil
-			}
-		}
-		pos++
+// findNext returns the rune index of the next occurrence of s, or EndOfInputError.
+func (x *Parser) findNext(s string) (int, error) {
+	base := x.byteOffset(x.position)
+	offset := strings.Index(x.input[base:], s)
+	if offset < 0 {
+		return 0, EndOfInputError{}
 	}
+	return x.position + utf8.RuneCountInString(x.input[base:base+offset]), nil
 }
@@ -6,7 +6,7 @@ func (x *Parser) CurrentIndex() int {
 }
 
 func (x *Parser) IsExhausted() bool {
-	return x.CurrentIndex() >= len(x.input)
+	return x.CurrentIndex() >= x.length()
 }
 
 func (x *Parser) HasMore() bool {
@@ -14,15 +14,15 @@ func (x *Parser) HasMore() bool {
 }
 
 func (x *Parser) Processed() string {
-	return string(x.input[0:x.position])
+	return x.slice(0, x.position)
 }
 
 func (x *Parser) Remaining() string {
-	return string(x.input[x.position:])
+	return x.input[x.byteOffset(x.position):]
 }
 
 func (x *Parser) RemainingRuneCount() int {
-	return len(x.input) - x.position
+	return x.length() - x.position
 }
 
 func (x *Parser) String() string {
@@ -32,7 +32,7 @@ func (x *Parser) String() string {
 func (x *Parser) CurrentContext() string {
 	const contextLength = 10
 	start := max(0, x.position-contextLength)
-	end := min(x.position+contextLength, len(x.input))
-	result := string(x.input[start:x.position]) + "|>" + string(x.input[x.position:end])
+	end := min(x.position+contextLength, x.length())
+	result := x.slice(start, x.position) + "|>" + x.slice(x.position, end)
 	return result
 }
@@ -7,7 +7,7 @@ import (
 
 func TestParser_RemainingRuneCount(t *testing.T) {
 	type fields struct {
-		input    []rune
+		input    string
 		position int
 	}
 	tests := []struct {
@@ -18,7 +18,7 @@ func TestParser_RemainingRuneCount(t *testing.T) {
 		{
 			name: "basic",
 			fields: fields{
-				input:    []rune("abc"),
+				input:    "abc",
 				position: 0,
 			},
 			want: 3,
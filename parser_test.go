@@ -3,6 +3,7 @@ package textparser
 import (
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"unicode"
 )
 
 func TestParser_Basic(t *testing.T) {
@@ -90,6 +91,19 @@ func TestParser_MustReadToRune(t *testing.T) {
 	a.True(p.LookingAtString(" 16"), p.CurrentContext())
 }
 
+func TestParser_ReadToRune(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("15 16")
+	_, err := p.ReadToRune(';')
+	a.ErrorIs(err, EndOfInputError{})
+	a.Equal(0, p.CurrentIndex())
+
+	p.SkipToEnd()
+	_, err = p.ReadToRune(';')
+	a.ErrorIs(err, EndOfInputError{})
+}
+
 func TestParser_MustReadToAnyString(t *testing.T) {
 	a := assert.New(t)
 
@@ -123,6 +137,37 @@ func TestParser_MustReadToAnyStringOrEnd(t *testing.T) {
 	a.True(p.IsExhausted(), p.CurrentContext())
 }
 
+func TestParser_ReadToAnyStringMatch(t *testing.T) {
+	a := assert.New(t)
+
+	delimiters := []string{"=", "->", ";"}
+	p := NewParser("a = b -> c; d")
+	a.Equal(AnyStringMatch{Content: "a ", Index: 0, Delimiter: "="}, p.MustReadToAnyStringMatch(delimiters, ConsumeDelimiter))
+	a.Equal(AnyStringMatch{Content: " b ", Index: 1, Delimiter: "->"}, p.MustReadToAnyStringMatch(delimiters, ConsumeDelimiter))
+	a.Equal(AnyStringMatch{Content: " c", Index: 2, Delimiter: ";"}, p.MustReadToAnyStringMatch(delimiters, LeaveDelimiter))
+	a.True(p.LookingAtString("; d"), p.CurrentContext())
+
+	p.MustSkip(1)
+	_, err := p.ReadToAnyStringMatch(delimiters, ConsumeDelimiter)
+	a.ErrorIs(err, EndOfInputError{})
+	a.Equal(AnyStringMatch{Content: " d", Index: -1}, p.MustReadToAnyStringOrEndMatch(delimiters, ConsumeDelimiter))
+	a.True(p.IsExhausted())
+}
+
+func TestParser_ReadToStopSetMatch(t *testing.T) {
+	a := assert.New(t)
+
+	delimiters := NewStopSet([]string{"=", "->", ";"})
+	p := NewParser("a = b -> c")
+	a.Equal(AnyStringMatch{Content: "a ", Index: 0, Delimiter: "="}, p.MustReadToStopSetMatch(delimiters, ConsumeDelimiter))
+	a.Equal(AnyStringMatch{Content: " b ", Index: 1, Delimiter: "->"}, p.MustReadToStopSetMatch(delimiters, LeaveDelimiter))
+	a.True(p.LookingAtString("-> c"), p.CurrentContext())
+
+	p.MustSkip(2)
+	a.Equal(AnyStringMatch{Content: " c", Index: -1}, p.MustReadToStopSetOrEndMatch(delimiters, ConsumeDelimiter))
+	a.True(p.IsExhausted())
+}
+
 func TestParser_MustReadToPositionString(t *testing.T) {
 	a := assert.New(t)
 
@@ -138,3 +183,33 @@ func TestParser_CurrentContext(t *testing.T) {
 	p.MustSkip(13)
 	a.Equal(`defghijklm|>nopqrstuvw`, p.CurrentContext())
 }
+
+func TestParser_ReadWhile(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("abc123 δέκα\tend")
+	a.Equal("abc", p.ReadWhile(unicode.IsLetter))
+	a.Equal("", p.ReadWhile(unicode.IsLetter))
+	a.Equal("123", p.ReadWhile(unicode.IsDigit))
+	a.Equal(" ", p.ReadUntil(unicode.IsLetter))
+	a.Equal("δέκα", p.ReadWhile(In(unicode.Greek)))
+	a.Equal("end", p.SkipWhile(unicode.IsSpace).ReadUntil(unicode.IsSpace))
+	a.True(p.IsExhausted())
+}
+
+func TestParser_ReadWhileN(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("2024-1-15")
+	year, err := p.ReadWhileN(4, 4, unicode.IsDigit)
+	a.Nil(err)
+	a.Equal("2024", year)
+
+	p.MustSkipString("-")
+	_, err = p.ReadWhileN(2, 2, unicode.IsDigit)
+	a.EqualError(err, `unexpected input at line 1, column 6, expected at least 2 matching runes, found "1-"`)
+	a.Equal(5, p.CurrentIndex())
+
+	a.Equal("1", p.MustReadWhileN(1, 2, unicode.IsDigit))
+	a.Equal("-15", p.MustReadWhileN(0, -1, func(r rune) bool { return true }))
+}
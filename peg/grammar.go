@@ -0,0 +1,380 @@
+// Package peg interprets parsing expression grammars at runtime.
+//
+// A grammar is a list of rules of the form
+//
+//	Name <- Expression
+//
+// where expressions are built from
+//
+//	"text" 'text'   literal, with the escapes \n \r \t \\ \" \'
+//	[a-z_]          character class, see textparser.CompileRuneSet
+//	.               any rune
+//	Name            another rule
+//	label:e         e, captured as a node named label
+//	( e )           grouping
+//	e* e+ e?        repetition and option
+//	&e !e           lookahead, consuming no input
+//	e1 e2           sequence
+//	e1 / e2         ordered choice
+//
+// Comments start with # and reach to the end of the line. The first rule is the start rule. Every rule whose name
+// doesn't start with an underscore adds a node to the parse tree, as do captures. Rules starting with an underscore
+// are inlined, which suits whitespace rules: their failures only show up in errors if nothing else failed there.
+package peg
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jojomi/textparser"
+)
+
+type exprKind int
+
+const (
+	literalExpr exprKind = iota
+	classExpr
+	anyExpr
+	ruleExpr
+	captureExpr
+	sequenceExpr
+	choiceExpr
+	zeroOrMoreExpr
+	oneOrMoreExpr
+	optionalExpr
+	andExpr
+	notExpr
+)
+
+// expr is a compiled parsing expression.
+type expr struct {
+	kind exprKind
+	// literal text, class source, rule name or capture label
+	text  string
+	class *textparser.RuneSet
+	rule  *rule
+	// operands of sequences, choices and all unary operators
+	items []*expr
+	// rune index of a rule reference in the grammar, to report undefined rules
+	index int
+}
+
+type rule struct {
+	name string
+	id   int
+	body *expr
+}
+
+// Grammar is a compiled grammar, safe for concurrent use.
+type Grammar struct {
+	rules map[string]*rule
+	start *rule
+}
+
+// Compile parses a grammar. Syntax errors are returned as *textparser.ParseError.
+func Compile(grammar string) (*Grammar, error) {
+	var (
+		p = textparser.NewParser(grammar)
+		g = &Grammar{rules: make(map[string]*rule)}
+	)
+	skipSpacing(p)
+	for p.HasMore() {
+		start := p.CurrentIndex()
+		name, err := readIdentifier(p)
+		if err != nil {
+			return nil, err
+		}
+		skipSpacing(p)
+		err = p.SkipString("<-")
+		if err != nil {
+			return nil, err
+		}
+		skipSpacing(p)
+		body, err := parseChoice(p)
+		if err != nil {
+			return nil, err
+		}
+
+		if g.rules[name] != nil {
+			return nil, p.NewParseErrorAt(start, textparser.InvalidArgument, "", "", fmt.Errorf("rule %s defined twice", name))
+		}
+		r := &rule{name: name, id: len(g.rules), body: body}
+		g.rules[name] = r
+		if g.start == nil {
+			g.start = r
+		}
+	}
+	if g.start == nil {
+		return nil, p.NewParseError(textparser.EndOfInput, "rule", "", nil)
+	}
+
+	for _, r := range g.rules {
+		err := g.resolve(p, r.body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+func MustCompile(grammar string) *Grammar {
+	g, err := Compile(grammar)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// Rule reports if the grammar has a rule called name.
+func (x *Grammar) Rule(name string) bool {
+	return x.rules[name] != nil
+}
+
+// resolve links the rule references in e to their rules. p is the parser of the grammar.
+func (x *Grammar) resolve(p *textparser.Parser, e *expr) error {
+	if e.kind == ruleExpr {
+		e.rule = x.rules[e.text]
+		if e.rule == nil {
+			return p.NewParseErrorAt(e.index, textparser.InvalidArgument, "", "", fmt.Errorf("undefined rule %s", e.text))
+		}
+	}
+	for _, item := range e.items {
+		err := x.resolve(p, item)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseChoice parses e1 / e2 / ….
+func parseChoice(p *textparser.Parser) (*expr, error) {
+	first, err := parseSequence(p)
+	if err != nil {
+		return nil, err
+	}
+	choice := &expr{kind: choiceExpr, items: []*expr{first}}
+	for p.LookingAtRune('/') {
+		p.MustSkip(1)
+		skipSpacing(p)
+		item, err := parseSequence(p)
+		if err != nil {
+			return nil, err
+		}
+		choice.items = append(choice.items, item)
+	}
+	if len(choice.items) == 1 {
+		return first, nil
+	}
+	return choice, nil
+}
+
+// parseSequence parses items up to the end of the expression, which is a /, a ), the end of input or the start of
+// the next rule.
+func parseSequence(p *textparser.Parser) (*expr, error) {
+	sequence := &expr{kind: sequenceExpr}
+	for p.HasMore() && !p.LookingAtRune('/') && !p.LookingAtRune(')') && !lookingAtRuleStart(p) {
+		item, err := parsePrefix(p)
+		if err != nil {
+			return nil, err
+		}
+		sequence.items = append(sequence.items, item)
+	}
+	if len(sequence.items) == 1 {
+		return sequence.items[0], nil
+	}
+	return sequence, nil
+}
+
+// parsePrefix parses an optional lookahead operator and its operand.
+func parsePrefix(p *textparser.Parser) (*expr, error) {
+	kind := exprKind(-1)
+	switch {
+	case p.LookingAtRune('&'):
+		kind = andExpr
+	case p.LookingAtRune('!'):
+		kind = notExpr
+	}
+	if kind < 0 {
+		return parseSuffix(p)
+	}
+
+	p.MustSkip(1)
+	skipSpacing(p)
+	operand, err := parseSuffix(p)
+	if err != nil {
+		return nil, err
+	}
+	return &expr{kind: kind, items: []*expr{operand}}, nil
+}
+
+// parseSuffix parses a primary expression and an optional repetition operator.
+func parseSuffix(p *textparser.Parser) (*expr, error) {
+	e, err := parsePrimary(p)
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := map[rune]exprKind{'*': zeroOrMoreExpr, '+': oneOrMoreExpr, '?': optionalExpr}
+	for {
+		r, err := p.GetNextRune()
+		kind, ok := kinds[r]
+		if err != nil || !ok {
+			return e, nil
+		}
+		p.MustSkip(1)
+		skipSpacing(p)
+		e = &expr{kind: kind, items: []*expr{e}}
+	}
+}
+
+func parsePrimary(p *textparser.Parser) (*expr, error) {
+	var (
+		e   *expr
+		err error
+	)
+	start := p.CurrentIndex()
+	switch {
+	case p.LookingAtRune('('):
+		p.MustSkip(1)
+		skipSpacing(p)
+		e, err = parseChoice(p)
+		if err == nil {
+			err = p.SkipString(")")
+		}
+	case p.LookingAtRune('"') || p.LookingAtRune('\''):
+		var text string
+		text, err = readLiteral(p)
+		e = &expr{kind: literalExpr, text: text}
+	case p.LookingAtRune('['):
+		e, err = readClass(p)
+	case p.LookingAtRune('.'):
+		p.MustSkip(1)
+		e = &expr{kind: anyExpr}
+	default:
+		var name string
+		name, err = readIdentifier(p)
+		if err != nil {
+			return nil, err
+		}
+		if !p.LookingAtRune(':') {
+			e = &expr{kind: ruleExpr, text: name, index: start}
+			break
+		}
+		p.MustSkip(1)
+		skipSpacing(p)
+		var operand *expr
+		operand, err = parseSuffix(p)
+		if err != nil {
+			return nil, err
+		}
+		return &expr{kind: captureExpr, text: name, items: []*expr{operand}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	skipSpacing(p)
+	return e, nil
+}
+
+// readLiteral reads a single or double quoted literal.
+func readLiteral(p *textparser.Parser) (string, error) {
+	quote := p.MustReadRune()
+	escapes := map[rune]rune{'n': '\n', 'r': '\r', 't': '\t', '\\': '\\', '"': '"', '\'': '\''}
+
+	var b strings.Builder
+	for {
+		start := p.CurrentIndex()
+		r, err := p.ReadRune()
+		if err != nil {
+			return "", p.NewParseError(textparser.EndOfInput, "closing "+strconv.QuoteRune(quote), "", nil)
+		}
+		switch r {
+		case quote:
+			if b.Len() == 0 {
+				return "", p.NewParseErrorAt(start-1, textparser.InvalidArgument, "", "", fmt.Errorf("empty literal"))
+			}
+			return b.String(), nil
+		case '\\':
+			escaped, err := p.ReadRune()
+			unescaped, ok := escapes[escaped]
+			if err != nil || !ok {
+				return "", p.NewParseErrorAt(start, textparser.UnexpectedInput, "escape sequence", p.MustExtract(start, p.CurrentIndex()), nil)
+			}
+			b.WriteRune(unescaped)
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
+
+// readClass reads a character class in brackets.
+func readClass(p *textparser.Parser) (*expr, error) {
+	start := p.CurrentIndex()
+	p.MustSkip(1)
+	if p.LookingAtRune('^') {
+		p.MustSkip(1)
+	}
+	// a leading ] is part of the class
+	if p.LookingAtRune(']') {
+		p.MustSkip(1)
+	}
+	for !p.LookingAtRune(']') {
+		r, err := p.ReadRune()
+		if err != nil {
+			return nil, p.NewParseError(textparser.EndOfInput, `"]"`, "", nil)
+		}
+		if r == '\\' {
+			_, _ = p.ReadRune()
+		}
+	}
+	p.MustSkip(1)
+
+	source := p.MustExtract(start, p.CurrentIndex())
+	set, err := textparser.CompileRuneSet(source)
+	var parseErr *textparser.ParseError
+	if errors.As(err, &parseErr) {
+		// the position refers to the class, not the grammar
+		return nil, p.NewParseErrorAt(start+parseErr.Position.Offset, parseErr.Kind, parseErr.Expected, parseErr.Found,
+			parseErr.Err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &expr{kind: classExpr, text: source, class: set}, nil
+}
+
+var identifierSet = textparser.MustCompileRuneSet(`[A-Za-z0-9_]`)
+
+func readIdentifier(p *textparser.Parser) (string, error) {
+	if p.LookingAtFunc(func(r rune) bool { return r >= '0' && r <= '9' }) || !p.LookingAtSet(identifierSet) {
+		return "", p.Unexpected("rule name", 10)
+	}
+	return p.ReadSet(identifierSet), nil
+}
+
+// lookingAtRuleStart determines if the next rule definition starts at the current position.
+func lookingAtRuleStart(p *textparser.Parser) bool {
+	mark := p.Mark()
+	defer p.Reset(mark)
+
+	_, err := readIdentifier(p)
+	if err != nil {
+		return false
+	}
+	skipSpacing(p)
+	return p.LookingAtString("<-")
+}
+
+// skipSpacing skips whitespace and comments.
+func skipSpacing(p *textparser.Parser) {
+	for {
+		_ = p.SkipAnyWhitespaces()
+		if !p.LookingAtRune('#') {
+			return
+		}
+		_ = p.SkipRestOfLine()
+	}
+}
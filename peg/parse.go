@@ -0,0 +1,468 @@
+package peg
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jojomi/textparser"
+)
+
+var (
+	// errNoMatch is the internal result of a rule that did not match, the details are collected by the matcher.
+	errNoMatch = errors.New("no match")
+)
+
+// Node is a node of the parse tree, created for rules and captures.
+type Node struct {
+	// Name is the name of the rule or the label of the capture.
+	Name string
+	Text string
+	// Span is the input the node covers, see textparser.Parser.SpanPositions for line and column information.
+	Span     textparser.Span
+	Children []*Node
+}
+
+// Child returns the first child called name, nil if there is none.
+func (x *Node) Child(name string) *Node {
+	for _, child := range x.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// ChildrenNamed returns all children called name.
+func (x *Node) ChildrenNamed(name string) []*Node {
+	var result []*Node
+	for _, child := range x.Children {
+		if child.Name == name {
+			result = append(result, child)
+		}
+	}
+	return result
+}
+
+// String returns the tree in a compact form like Sum(Number "1" Number "2"). Leaves show their text.
+func (x *Node) String() string {
+	var b strings.Builder
+	x.write(&b)
+	return b.String()
+}
+
+func (x *Node) write(b *strings.Builder) {
+	b.WriteString(x.Name)
+	if len(x.Children) == 0 {
+		b.WriteString(" " + strconv.Quote(x.Text))
+		return
+	}
+	b.WriteString("(")
+	for i, child := range x.Children {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		child.write(b)
+	}
+	b.WriteString(")")
+}
+
+// Error is returned if the input does not match the grammar. It describes the furthest position reached.
+type Error struct {
+	Position textparser.Position
+	// Rule is the outermost rule starting at Position, or the innermost rule enclosing all failures there.
+	Rule string
+	// Expected lists the alternatives that would have matched at Position, in the order they were tried. Rules
+	// called by Rule that fail right at Position are listed by name.
+	Expected []string
+	// Found is a short excerpt of the input at Position.
+	Found string
+}
+
+var _ textparser.PositionedError = (*Error)(nil)
+
+func (x *Error) Error() string {
+	return textparser.FormatFailure(x.Position, "in rule "+x.Rule, x.Expected, x.Found)
+}
+
+func (x *Error) ErrorPosition() textparser.Position {
+	return x.Position
+}
+
+func (x *Error) ErrorFound() string {
+	return x.Found
+}
+
+func (x *Error) ErrorMessage() string {
+	return textparser.FormatFailureMessage("in rule "+x.Rule, x.Expected, x.Found)
+}
+
+// Is makes errors.Is(err, textparser.EndOfInputError{}) work for failures at the end of input.
+func (x *Error) Is(target error) bool {
+	return target == textparser.EndOfInputError{} && x.Found == ""
+}
+
+// Parse matches the start rule at the current position of p. The input after the match is not consumed, use !. at
+// the end of the start rule to require the end of input. On failure, the parser is reset to where it started and
+// the error is an *Error.
+//
+// Parsing takes exponential time in the worst case. Enable packrat parsing using textparser.Parser.EnableMemo to
+// make it linear at the cost of memory. This is also required for left-recursive rules, which fail otherwise. Every
+// parse starts with an empty memo table, see textparser.Parser.ClearMemo.
+func (x *Grammar) Parse(p *textparser.Parser) (*Node, error) {
+	return x.parse(p, x.start)
+}
+
+func (x *Grammar) MustParse(p *textparser.Parser) *Node {
+	node, err := x.Parse(p)
+	if err != nil {
+		panic(err)
+	}
+	return node
+}
+
+// ParseRule is like Parse, but starts with the rule called name.
+func (x *Grammar) ParseRule(p *textparser.Parser, name string) (*Node, error) {
+	r := x.rules[name]
+	if r == nil {
+		return nil, p.NewParseError(textparser.InvalidArgument, "", "", fmt.Errorf("undefined rule %s", name))
+	}
+	return x.parse(p, r)
+}
+
+func (x *Grammar) parse(p *textparser.Parser, start *rule) (*Node, error) {
+	var (
+		mark = p.Mark()
+		m    = &matcher{p: p, furthest: -1}
+	)
+	// memoized results of an earlier parse lack the failures the matcher collects for error reporting
+	p.ClearMemo()
+	children, ok := m.matchRule(start)
+	if m.abort != nil {
+		p.Reset(mark)
+		return nil, m.abort
+	}
+	if !ok {
+		p.Reset(mark)
+		return nil, m.error(start)
+	}
+
+	if len(children) == 1 && children[0].Name == start.name {
+		return children[0], nil
+	}
+	// an inline start rule
+	return m.node(start.name, mark.Index(), children), nil
+}
+
+// frame is a rule being matched.
+type frame struct {
+	name  string
+	start int
+}
+
+// failure is a terminal that did not match at the furthest position.
+type failure struct {
+	expected string
+	// rules being matched when it failed, outermost first, without inline rules
+	frames []frame
+	// if it failed within an inline rule
+	inline bool
+}
+
+// matcher holds the state of a single parse.
+type matcher struct {
+	p *textparser.Parser
+
+	// rules being matched, innermost last
+	frames []frame
+	// nesting depth of lookahead, where failures are not reported
+	lookahead int
+
+	// furthest index a terminal failed at and the failures there
+	furthest int
+	failures []failure
+
+	// abort is the error ending matching early, for an exceeded parser limit or left recursion that memoization did
+	// not resolve
+	abort error
+}
+
+// fail records that expected did not match at the current position.
+func (x *matcher) fail(expected string) {
+	pos := x.p.CurrentIndex()
+	if x.lookahead > 0 || pos < x.furthest {
+		return
+	}
+	if pos > x.furthest {
+		x.furthest = pos
+		x.failures = x.failures[:0]
+	}
+
+	f := failure{expected: expected}
+	for _, fr := range x.frames {
+		if strings.HasPrefix(fr.name, "_") {
+			f.inline = true
+			continue
+		}
+		f.inline = false
+		f.frames = append(f.frames, fr)
+	}
+	x.failures = append(x.failures, f)
+}
+
+// error describes the failures at the furthest position. They are reported in the outermost rule starting there, or
+// the innermost rule enclosing all of them. Failures in rules called by the reported rule are summarized by the name
+// of the called rule if it starts at the furthest position, too. Failures in inline rules, usually for whitespace,
+// are only reported if there are no others.
+func (x *matcher) error(start *rule) *Error {
+	failures := make([]failure, 0, len(x.failures))
+	for _, f := range x.failures {
+		if !f.inline {
+			failures = append(failures, f)
+		}
+	}
+	if len(failures) == 0 {
+		failures = x.failures
+	}
+
+	var common []frame
+	for i, f := range failures {
+		if i == 0 {
+			common = f.frames
+			continue
+		}
+		n := 0
+		for n < len(common) && n < len(f.frames) && common[n] == f.frames[n] {
+			n++
+		}
+		common = common[:n]
+	}
+
+	var (
+		ruleIndex = len(common) - 1
+		result    = &Error{Rule: start.name}
+	)
+	for i, fr := range common {
+		if fr.start == x.furthest {
+			ruleIndex = i
+			break
+		}
+	}
+	if ruleIndex >= 0 {
+		result.Rule = common[ruleIndex].name
+	}
+
+	for _, f := range failures {
+		expected := f.expected
+		if len(f.frames) > ruleIndex+1 && f.frames[ruleIndex+1].start == x.furthest {
+			expected = f.frames[ruleIndex+1].name
+		}
+		if !slices.Contains(result.Expected, expected) {
+			result.Expected = append(result.Expected, expected)
+		}
+	}
+
+	result.Position = x.p.PositionAt(x.furthest)
+	if end := min(x.furthest+10, x.p.CurrentIndex()+x.p.RemainingRuneCount()); x.furthest >= 0 && end > x.furthest {
+		result.Found = x.p.MustExtract(x.furthest, end)
+	}
+	return result
+}
+
+// lookaheadRule is the memo key of a rule matched within a lookahead. Failures are not recorded there, so its results
+// must not be reused outside.
+type lookaheadRule struct {
+	*rule
+}
+
+func (x *matcher) matchRule(r *rule) ([]*Node, bool) {
+	if x.abort != nil {
+		return nil, false
+	}
+	var key any = r
+	if x.lookahead > 0 {
+		key = lookaheadRule{r}
+	}
+	children, err := textparser.Memoize(x.p, key, func(p *textparser.Parser) ([]*Node, error) {
+		if err := p.Step(); err != nil {
+			return nil, err
+		}
+		if err := p.EnterNesting(); err != nil {
+			return nil, err
+		}
+		defer p.ExitNesting()
+
+		start := p.CurrentIndex()
+		for _, fr := range x.frames {
+			if fr.name == r.name && fr.start == start {
+				// matching another alternative instead would silently produce a wrong tree
+				x.abort = p.NewParseError(textparser.InvalidArgument, "", "",
+					fmt.Errorf("left recursion in rule %s, enable memoization using Parser.EnableMemo", r.name))
+				return nil, x.abort
+			}
+		}
+
+		x.frames = append(x.frames, frame{name: r.name, start: start})
+		children, ok := x.match(r.body)
+		x.frames = x.frames[:len(x.frames)-1]
+		if !ok {
+			return nil, errNoMatch
+		}
+
+		if strings.HasPrefix(r.name, "_") {
+			return children, nil
+		}
+		return []*Node{x.node(r.name, start, children)}, nil
+	})
+	var limitErr *textparser.LimitError
+	if errors.As(err, &limitErr) {
+		x.abort = err
+	}
+	return children, err == nil
+}
+
+// match matches e at the current position and returns the nodes created. On failure, the parser may be left
+// anywhere, the caller resets it.
+func (x *matcher) match(e *expr) ([]*Node, bool) {
+	p := x.p
+	switch e.kind {
+	case literalExpr:
+		if !p.LookingAtString(e.text) {
+			x.fail(strconv.Quote(e.text))
+			return nil, false
+		}
+		p.MustSkip(len([]rune(e.text)))
+		return nil, true
+
+	case classExpr:
+		r, err := p.GetNextRune()
+		if err != nil || !e.class.Contains(r) {
+			x.fail(e.text)
+			return nil, false
+		}
+		p.MustSkip(1)
+		return nil, true
+
+	case anyExpr:
+		if p.IsExhausted() {
+			x.fail("any rune")
+			return nil, false
+		}
+		p.MustSkip(1)
+		return nil, true
+
+	case ruleExpr:
+		return x.matchRule(e.rule)
+
+	case captureExpr:
+		start := p.CurrentIndex()
+		children, ok := x.match(e.items[0])
+		if !ok {
+			return nil, false
+		}
+		return []*Node{x.node(e.text, start, children)}, true
+
+	case sequenceExpr:
+		var result []*Node
+		for _, item := range e.items {
+			children, ok := x.match(item)
+			if !ok {
+				return nil, false
+			}
+			result = append(result, children...)
+		}
+		return result, true
+
+	case choiceExpr:
+		mark := p.Mark()
+		for _, item := range e.items {
+			children, ok := x.match(item)
+			if ok {
+				return children, true
+			}
+			p.Reset(mark)
+		}
+		return nil, false
+
+	case zeroOrMoreExpr, oneOrMoreExpr:
+		var (
+			result []*Node
+			count  = 0
+		)
+		for {
+			mark := p.Mark()
+			children, ok := x.match(e.items[0])
+			if !ok {
+				p.Reset(mark)
+				break
+			}
+			result = append(result, children...)
+			count++
+			// e* and e+ end once e matches nothing, like in other PEG implementations
+			if p.CurrentIndex() == mark.Index() {
+				break
+			}
+		}
+		return result, count > 0 || e.kind == zeroOrMoreExpr
+
+	case optionalExpr:
+		mark := p.Mark()
+		children, ok := x.match(e.items[0])
+		if !ok {
+			p.Reset(mark)
+			return nil, true
+		}
+		return children, true
+
+	case andExpr, notExpr:
+		mark := p.Mark()
+		x.lookahead++
+		_, ok := x.match(e.items[0])
+		x.lookahead--
+		p.Reset(mark)
+		if ok != (e.kind == andExpr) {
+			x.fail(describe(e))
+			return nil, false
+		}
+		return nil, true
+	}
+	panic(fmt.Sprintf("unknown expression kind %d", e.kind))
+}
+
+func (x *matcher) node(name string, start int, children []*Node) *Node {
+	end := x.p.CurrentIndex()
+	text := ""
+	if end > start {
+		text = x.p.MustExtract(start, end)
+	}
+	return &Node{
+		Name:     name,
+		Text:     text,
+		Span:     textparser.Span{Start: start, End: end},
+		Children: children,
+	}
+}
+
+// describe formats e for error messages.
+func describe(e *expr) string {
+	switch e.kind {
+	case literalExpr:
+		return strconv.Quote(e.text)
+	case classExpr, ruleExpr:
+		return e.text
+	case anyExpr:
+		return "any rune"
+	case captureExpr:
+		return describe(e.items[0])
+	case andExpr:
+		return "&" + describe(e.items[0])
+	case notExpr:
+		if e.items[0].kind == anyExpr {
+			return "end of input"
+		}
+		return "not " + describe(e.items[0])
+	}
+	return "expression"
+}
@@ -0,0 +1,271 @@
+package peg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jojomi/textparser"
+	"github.com/stretchr/testify/assert"
+)
+
+const listGrammar = `
+# a list of key value pairs
+List    <- _Spacing Pair (',' _Spacing Pair)* !.
+Pair    <- key:Name _Spacing '=' _Spacing Value _Spacing
+Value   <- Number / String / "true" / "false"
+Number  <- '-'? [0-9]+ ('.' [0-9]+)?
+String  <- '"' ( !'"' ('\\' . / .) )* '"'
+Name    <- [a-zA-Z_] [a-zA-Z_0-9]*
+_Spacing <- [ \t\n]*
+`
+
+func TestGrammar_Parse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "single pair",
+			input: "a = 1",
+			want:  `List(Pair(key(Name "a") Value(Number "1")))`,
+		},
+		{
+			name:  "several pairs",
+			input: ` x=-1.5, label = "say \"hi\"", on=true `,
+			want:  `List(Pair(key(Name "x") Value(Number "-1.5")) Pair(key(Name "label") Value(String "\"say \\\"hi\\\"\"")) Pair(key(Name "on") Value "true"))`,
+		},
+	}
+	g := MustCompile(listGrammar)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := textparser.NewParser(tt.input)
+			node, err := g.Parse(p)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, node.String())
+			assert.True(t, p.IsExhausted())
+		})
+	}
+}
+
+func TestGrammar_ParseSpans(t *testing.T) {
+	a := assert.New(t)
+
+	p := textparser.NewParser("a = 1,\nbb = 22")
+	node := MustCompile(listGrammar).MustParse(p)
+	pairs := node.ChildrenNamed("Pair")
+	a.Len(pairs, 2)
+
+	name := pairs[1].Child("key").Child("Name")
+	a.Equal("bb", name.Text)
+	a.Equal(textparser.Span{Start: 7, End: 9}, name.Span)
+	start, end := p.SpanPositions(name.Span)
+	a.Equal(textparser.Position{Offset: 7, Line: 2, Column: 1, ByteOffset: 7}, start)
+	a.Equal(textparser.Position{Offset: 9, Line: 2, Column: 3, ByteOffset: 9}, end)
+	a.Nil(pairs[1].Child("missing"))
+}
+
+func TestGrammar_ParseErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantError string
+	}{
+		{
+			name:      "furthest failure",
+			input:     "a = 1, b = x",
+			wantError: `unexpected input at line 1, column 12 in rule Value, expected one of Number, String, "true", "false", found "x"`,
+		},
+		{
+			name:      "innermost rule",
+			input:     "a = 1.x",
+			wantError: `unexpected input at line 1, column 7 in rule Number, expected [0-9], found "x"`,
+		},
+		{
+			name:      "end of input",
+			input:     "a = ",
+			wantError: `unexpected end of input at line 1, column 5 in rule Value, expected one of Number, String, "true", "false"`,
+		},
+		{
+			name:      "trailing input",
+			input:     "a = 1 b",
+			wantError: `unexpected input at line 1, column 7 in rule List, expected one of ",", end of input, found "b"`,
+		},
+	}
+	g := MustCompile(listGrammar)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := textparser.NewParser(tt.input)
+			_, err := g.Parse(p)
+			assert.EqualError(t, err, tt.wantError)
+			assert.Equal(t, 0, p.CurrentIndex())
+
+			// parsing again reports the same error, although the first parse filled the memo table
+			p = textparser.NewParser(tt.input).EnableMemo()
+			for i := 0; i < 2; i++ {
+				_, err = g.Parse(p)
+				assert.EqualError(t, err, tt.wantError)
+			}
+		})
+	}
+
+	_, err := MustCompile(listGrammar).Parse(textparser.NewParser(""))
+	assert.ErrorIs(t, err, textparser.EndOfInputError{})
+
+	p := textparser.NewParser("a = 1,\nbb = x")
+	_, err = g.Parse(p)
+	assert.Equal(t, `2:6: error: unexpected input in rule Value, expected one of Number, String, "true", "false", found "x"
+  |
+2 | bb = x
+  |      ^
+`, p.RenderError(err, textparser.DiagnosticOptions{}))
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name      string
+		grammar   string
+		wantError string
+	}{
+		{
+			name:      "empty",
+			grammar:   " # nothing",
+			wantError: "unexpected end of input at line 1, column 11, expected rule",
+		},
+		{
+			name:      "undefined rule",
+			grammar:   "A <- 'a' B",
+			wantError: "invalid argument at line 1, column 10: undefined rule B",
+		},
+		{
+			name:      "duplicate rule",
+			grammar:   "A <- 'a'\nA <- 'b'",
+			wantError: "invalid argument at line 2, column 1: rule A defined twice",
+		},
+		{
+			name:      "missing arrow",
+			grammar:   "A = 'a'",
+			wantError: `unexpected input at line 1, column 3, expected "<-", found "= "`,
+		},
+		{
+			name:      "unterminated literal",
+			grammar:   "A <- 'a",
+			wantError: `unexpected end of input at line 1, column 8, expected closing '\''`,
+		},
+		{
+			name:      "invalid class",
+			grammar:   `A <- [z-a]`,
+			wantError: `unexpected input at line 1, column 9, expected end of range, found "a"`,
+		},
+		{
+			name:      "invalid class on a later line",
+			grammar:   "A <- 'a' B\nB <- [z-a]",
+			wantError: `unexpected input at line 2, column 9, expected end of range, found "a"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.grammar)
+			assert.EqualError(t, err, tt.wantError)
+		})
+	}
+}
+
+func TestGrammar_ParseRule(t *testing.T) {
+	a := assert.New(t)
+
+	g := MustCompile(listGrammar)
+	a.True(g.Rule("Number"))
+	p := textparser.NewParser("42 rest")
+	a.Equal(`Number "42"`, nodeString(g.ParseRule(p, "Number")))
+	a.Equal(" rest", p.Remaining())
+
+	_, err := g.ParseRule(p, "Nope")
+	var parseErr *textparser.ParseError
+	a.True(errors.As(err, &parseErr))
+	a.Equal(textparser.InvalidArgument, parseErr.Kind)
+}
+
+func nodeString(node *Node, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return node.String()
+}
+
+func TestGrammar_ParseMemo(t *testing.T) {
+	a := assert.New(t)
+
+	g := MustCompile(`
+		Sum    <- Sum '+' Number / Sum '-' Number / Number
+		Number <- [0-9]+
+	`)
+
+	p := textparser.NewParser("1+2-3").EnableMemo()
+	node, err := g.Parse(p)
+	a.Nil(err)
+	a.Equal(`Sum(Sum(Sum(Number "1") Number "2") Number "3")`, node.String())
+	a.True(p.IsExhausted())
+	a.Positive(p.MemoStats().Hits)
+
+	// rules first matched within a lookahead report their failures later on
+	g2 := MustCompile(`
+		S <- !(N "x") N "y"
+		N <- "a" "b"
+	`)
+	for _, p := range []*textparser.Parser{textparser.NewParser("ac"), textparser.NewParser("ac").EnableMemo()} {
+		_, err = g2.Parse(p)
+		a.EqualError(err, `unexpected input at line 1, column 2 in rule N, expected "b", found "c"`)
+	}
+
+	// without memoization, left recursion is an error instead of a tree of just the first number
+	p = textparser.NewParser("1+2-3")
+	_, err = g.Parse(p)
+	var parseErr *textparser.ParseError
+	a.True(errors.As(err, &parseErr))
+	a.Equal(textparser.InvalidArgument, parseErr.Kind)
+	a.ErrorContains(err, "left recursion in rule Sum")
+	a.Equal(0, p.CurrentIndex())
+
+	// indirect left recursion, too
+	g = MustCompile(`
+		A <- B 'x' / 'y'
+		B <- A
+	`)
+	_, err = g.Parse(textparser.NewParser("yx"))
+	a.ErrorContains(err, "left recursion in rule A")
+}
+
+func TestGrammar_ParseLimits(t *testing.T) {
+	a := assert.New(t)
+
+	g := MustCompile(`
+		Value <- '[' Value? ']'
+	`)
+	input := strings.Repeat("[", 100) + strings.Repeat("]", 100)
+
+	p := textparser.NewParser(input).SetLimits(textparser.Limits{MaxDepth: 50})
+	_, err := g.Parse(p)
+	var limitErr *textparser.LimitError
+	a.True(errors.As(err, &limitErr))
+	a.Equal("depth", limitErr.Limit)
+	a.Equal(0, p.CurrentIndex())
+
+	// raising the limits lets the same parser succeed
+	p = textparser.NewParser(input).EnableMemo().SetLimits(textparser.Limits{MaxDepth: 50})
+	_, err = g.Parse(p)
+	a.True(errors.As(err, &limitErr))
+	_, err = g.Parse(p.SetLimits(textparser.Limits{MaxDepth: 101}))
+	a.Nil(err)
+
+	p = textparser.NewParser(input).SetLimits(textparser.Limits{MaxSteps: 50})
+	_, err = g.Parse(p)
+	a.True(errors.As(err, &limitErr))
+	a.Equal("steps", limitErr.Limit)
+
+	// the innermost Value? tries one more level
+	p = textparser.NewParser(input).SetLimits(textparser.Limits{MaxDepth: 101})
+	_, err = g.Parse(p)
+	a.Nil(err)
+}
@@ -0,0 +1,66 @@
+package textparser
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Position describes a location in the input. Line and Column are 1-based, Offset and ByteOffset are 0-based.
+type Position struct {
+	// Offset is the rune index, as returned by CurrentIndex.
+	Offset int
+	// Line is the 1-based line number.
+	Line int
+	// Column is the 1-based column, counted in runes.
+	Column int
+	// ByteOffset is the offset in the UTF-8 encoded input.
+	ByteOffset int
+}
+
+func (x Position) String() string {
+	return fmt.Sprintf("line %d, column %d", x.Line, x.Column)
+}
+
+// CurrentPosition returns the position of the parser.
+func (x *Parser) CurrentPosition() Position {
+	return x.PositionAt(x.position)
+}
+
+// PositionAt converts a rune index to a Position. Indices outside the input are clamped.
+func (x *Parser) PositionAt(index int) Position {
+	index = max(0, min(index, x.length()))
+	lineStarts := x.lineIndex()
+
+	// the last line start that is not after index
+	line := sort.Search(len(lineStarts), func(i int) bool {
+		return lineStarts[i] > index
+	}) - 1
+
+	return Position{
+		Offset:     index,
+		Line:       line + 1,
+		Column:     index - lineStarts[line] + 1,
+		ByteOffset: x.byteOffset(index),
+	}
+}
+
+// lineIndex returns the rune indices where lines start. They are computed on first use only.
+func (x *Parser) lineIndex() []int {
+	if x.lineStarts != nil {
+		return x.lineStarts
+	}
+
+	var (
+		lineStarts = []int{0}
+		index      = 0
+	)
+	for _, r := range x.input {
+		index++
+		if r == '\n' {
+			lineStarts = append(lineStarts, index)
+		}
+	}
+
+	x.lineStarts = lineStarts
+	return x.lineStarts
+}
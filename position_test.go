@@ -0,0 +1,45 @@
+package textparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParser_PositionAt(t *testing.T) {
+	input := "ab\nöü\n\nx"
+
+	tests := []struct {
+		name  string
+		index int
+		want  Position
+	}{
+		{"start", 0, Position{Offset: 0, Line: 1, Column: 1, ByteOffset: 0}},
+		{"first line", 1, Position{Offset: 1, Line: 1, Column: 2, ByteOffset: 1}},
+		{"newline", 2, Position{Offset: 2, Line: 1, Column: 3, ByteOffset: 2}},
+		{"second line", 3, Position{Offset: 3, Line: 2, Column: 1, ByteOffset: 3}},
+		{"multibyte", 4, Position{Offset: 4, Line: 2, Column: 2, ByteOffset: 5}},
+		{"empty line", 6, Position{Offset: 6, Line: 3, Column: 1, ByteOffset: 8}},
+		{"last line", 7, Position{Offset: 7, Line: 4, Column: 1, ByteOffset: 9}},
+		{"end of input", 8, Position{Offset: 8, Line: 4, Column: 2, ByteOffset: 10}},
+		{"beyond end", 100, Position{Offset: 8, Line: 4, Column: 2, ByteOffset: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewParser(input).PositionAt(tt.index))
+		})
+	}
+}
+
+func TestParser_CurrentPosition(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("name: Tom\nrole: CEO")
+	p.MustSkipRestOfLine().MustSkipNewlines().MustSkipString("role: ")
+	a.Equal(Position{Offset: 16, Line: 2, Column: 7, ByteOffset: 16}, p.CurrentPosition())
+	a.Equal("line 2, column 7", p.CurrentPosition().String())
+
+	err := p.SkipString("CTO")
+	a.ErrorContains(err, "line 2, column 7")
+}
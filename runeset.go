@@ -0,0 +1,306 @@
+package textparser
+
+import (
+	"fmt"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RuneSet is a compiled set of runes with fast membership lookup. Create it using CompileRuneSet or RuneSetOf and
+// combine sets using Union, Intersect and Negate. The zero value is an empty set.
+type RuneSet struct {
+	// membership of ASCII runes as a bitmap
+	ascii [2]uint64
+	// membership of all other runes
+	match func(r rune) bool
+}
+
+type runeRange struct {
+	lo, hi rune
+}
+
+// newRuneSet creates a set from a membership function, caching the result for ASCII.
+func newRuneSet(match func(r rune) bool) *RuneSet {
+	set := &RuneSet{match: match}
+	for r := rune(0); r < utf8.RuneSelf; r++ {
+		if match(r) {
+			set.ascii[r>>6] |= 1 << (r & 63)
+		}
+	}
+	return set
+}
+
+// RuneSetOf creates a set containing exactly the given runes.
+func RuneSetOf(runes ...rune) *RuneSet {
+	ranges := make([]runeRange, len(runes))
+	for i, r := range runes {
+		ranges[i] = runeRange{r, r}
+	}
+	return newRuneSet(rangeMatcher(ranges))
+}
+
+// CompileRuneSet compiles a character class like `[A-Za-z0-9_\-]`, `[^\n"]` or `\p{L}`.
+//
+// Within brackets, a leading ^ negates the class, a-z denotes a range, and a - at the start or end is literal.
+// Supported escapes are \n, \r, \t, \\, \], \[, \-, \^, \d (ASCII digits), \s (whitespace), \w (ASCII word runes),
+// \p{Name} and \P{Name} for Unicode categories and scripts (single letter names may omit the braces, e.g. \pL).
+// A single escape can be used without brackets.
+func CompileRuneSet(expr string) (*RuneSet, error) {
+	p := NewParser(expr)
+	var (
+		match func(rune) bool
+		err   error
+	)
+	if p.LookingAtRune('[') {
+		match, err = p.parseRuneClass()
+	} else {
+		var single rune
+		match, err = p.parseRuneSetItem(&single)
+		if err == nil && match == nil {
+			match = rangeMatcher([]runeRange{{single, single}})
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.HasMore() {
+		return nil, p.NewParseError(UnexpectedInput, "end of rune set", p.GetNextMax(10), nil)
+	}
+	return newRuneSet(match), nil
+}
+
+func MustCompileRuneSet(expr string) *RuneSet {
+	set, err := CompileRuneSet(expr)
+	if err != nil {
+		panic(err)
+	}
+	return set
+}
+
+// Contains determines if r is part of the set. It can be used as a predicate for ReadWhile and friends.
+func (x *RuneSet) Contains(r rune) bool {
+	if r >= 0 && r < utf8.RuneSelf {
+		return x.ascii[r>>6]&(1<<(r&63)) != 0
+	}
+	return x.matchOther(r)
+}
+
+// matchOther determines if the non-ASCII rune r is part of the set.
+func (x *RuneSet) matchOther(r rune) bool {
+	return x.match != nil && x.match(r)
+}
+
+// Union returns a set containing the runes contained in x or any of others.
+func (x *RuneSet) Union(others ...*RuneSet) *RuneSet {
+	result := &RuneSet{
+		ascii: x.ascii,
+		match: func(r rune) bool {
+			if x.matchOther(r) {
+				return true
+			}
+			for _, other := range others {
+				if other.matchOther(r) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+	for _, other := range others {
+		result.ascii[0] |= other.ascii[0]
+		result.ascii[1] |= other.ascii[1]
+	}
+	return result
+}
+
+// Intersect returns a set containing the runes contained in both x and other.
+func (x *RuneSet) Intersect(other *RuneSet) *RuneSet {
+	return &RuneSet{
+		ascii: [2]uint64{x.ascii[0] & other.ascii[0], x.ascii[1] & other.ascii[1]},
+		match: func(r rune) bool {
+			return x.matchOther(r) && other.matchOther(r)
+		},
+	}
+}
+
+// Negate returns a set containing all runes not contained in x.
+func (x *RuneSet) Negate() *RuneSet {
+	return &RuneSet{
+		ascii: [2]uint64{^x.ascii[0], ^x.ascii[1]},
+		match: func(r rune) bool {
+			return !x.matchOther(r)
+		},
+	}
+}
+
+// LookingAtSet determines if the next rune is contained in set.
+func (x *Parser) LookingAtSet(set *RuneSet) bool {
+	return x.LookingAtFunc(set.Contains)
+}
+
+// ReadSet reads runes as long as they are contained in set. The result may be empty.
+func (x *Parser) ReadSet(set *RuneSet) string {
+	return x.ReadWhile(set.Contains)
+}
+
+// SkipSet skips runes as long as they are contained in set (chainable).
+func (x *Parser) SkipSet(set *RuneSet) *Parser {
+	return x.SkipWhile(set.Contains)
+}
+
+// parseRuneClass parses a bracket expression.
+func (x *Parser) parseRuneClass() (func(rune) bool, error) {
+	err := x.SkipString("[")
+	if err != nil {
+		return nil, err
+	}
+	negated := x.LookingAtRune('^')
+	if negated {
+		x.MustSkip(1)
+	}
+
+	var (
+		ranges []runeRange
+		funcs  []func(rune) bool
+	)
+	for first := true; ; first = false {
+		if x.IsExhausted() {
+			return nil, x.NewParseError(EndOfInput, `"]"`, "", nil)
+		}
+		if x.LookingAtRune(']') && !first {
+			x.MustSkip(1)
+			break
+		}
+
+		lo, isRune, f, err := x.parseRuneClassAtom()
+		if err != nil {
+			return nil, err
+		}
+		if !isRune {
+			funcs = append(funcs, f)
+			continue
+		}
+
+		// range, unless the dash is the last rune of the class
+		hi := lo
+		if x.LookingAtRune('-') && !x.LookingAtString("-]") {
+			x.MustSkip(1)
+			start := x.position
+			hi, isRune, _, err = x.parseRuneClassAtom()
+			if err != nil {
+				return nil, err
+			}
+			if !isRune || hi < lo {
+				return nil, x.NewParseErrorAt(start, UnexpectedInput, "end of range", x.slice(start, x.position), nil)
+			}
+		}
+		ranges = append(ranges, runeRange{lo, hi})
+	}
+
+	inRanges := rangeMatcher(ranges)
+	return func(r rune) bool {
+		contained := inRanges(r)
+		for i := 0; !contained && i < len(funcs); i++ {
+			contained = funcs[i](r)
+		}
+		return contained != negated
+	}, nil
+}
+
+// parseRuneClassAtom parses a single rune or class escape within brackets.
+func (x *Parser) parseRuneClassAtom() (r rune, isRune bool, f func(rune) bool, err error) {
+	if !x.LookingAtRune('\\') {
+		r, err = x.ReadRune()
+		return r, true, nil, err
+	}
+
+	var single rune
+	f, err = x.parseRuneSetItem(&single)
+	if err != nil {
+		return 0, false, nil, err
+	}
+	if f == nil {
+		return single, true, nil, nil
+	}
+	return 0, false, f, nil
+}
+
+// parseRuneSetItem parses an escape sequence. Escapes for single runes are stored in single,
+// returning a nil func.
+func (x *Parser) parseRuneSetItem(single *rune) (func(rune) bool, error) {
+	err := x.SkipString(`\`)
+	if err != nil {
+		return nil, err
+	}
+	start := x.position - 1
+	escaped, err := x.ReadRune()
+	if err != nil {
+		return nil, err
+	}
+
+	switch escaped {
+	case 'd':
+		return func(r rune) bool { return r >= '0' && r <= '9' }, nil
+	case 's':
+		return unicode.IsSpace, nil
+	case 'w':
+		return func(r rune) bool {
+			return r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		}, nil
+	case 'p', 'P':
+		name := ""
+		if x.LookingAtRune('{') {
+			name, err = x.ReadToMatchingRuneSkipDelims('{', '}')
+		} else {
+			var r rune
+			r, err = x.ReadRune()
+			name = string(r)
+		}
+		if err != nil {
+			return nil, err
+		}
+		table := unicode.Categories[name]
+		if table == nil {
+			table = unicode.Scripts[name]
+		}
+		if table == nil {
+			return nil, x.NewParseErrorAt(start, UnexpectedInput, "Unicode category or script", name, nil)
+		}
+		negated := escaped == 'P'
+		return func(r rune) bool {
+			return unicode.Is(table, r) != negated
+		}, nil
+	}
+
+	escapes := map[rune]rune{'n': '\n', 'r': '\r', 't': '\t', '\\': '\\', ']': ']', '[': '[', '-': '-', '^': '^'}
+	r, ok := escapes[escaped]
+	if !ok {
+		return nil, x.NewParseErrorAt(start, UnexpectedInput, "escape sequence", fmt.Sprintf(`\%c`, escaped), nil)
+	}
+	*single = r
+	return nil, nil
+}
+
+// rangeMatcher returns a membership function for the union of ranges.
+func rangeMatcher(ranges []runeRange) func(rune) bool {
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].lo < ranges[j].lo
+	})
+	merged := make([]runeRange, 0, len(ranges))
+	for _, r := range ranges {
+		last := len(merged) - 1
+		if last >= 0 && r.lo <= merged[last].hi+1 {
+			merged[last].hi = max(merged[last].hi, r.hi)
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	return func(r rune) bool {
+		i := sort.Search(len(merged), func(i int) bool {
+			return merged[i].hi >= r
+		})
+		return i < len(merged) && merged[i].lo <= r
+	}
+}
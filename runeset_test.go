@@ -0,0 +1,107 @@
+package textparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompileRuneSet(t *testing.T) {
+	tests := []struct {
+		expr string
+		in   string
+		out  string
+	}{
+		{`[A-Za-z0-9_\-]`, "aZ09_-", " .äÄ]"},
+		{`[^\n"]`, "a ä\t'", "\n\""},
+		{`[-a]`, "-a", "b"},
+		{`[a-]`, "-a", "b"},
+		{`[]a]`, "]a", "["},
+		{`[\]\\\[\^]`, `]\[^`, "a"},
+		{`\p{L}`, "aäΩ", "1 _"},
+		{`\pN`, "1٣", "a"},
+		{`[\P{L}]`, "1 _", "aä"},
+		{`[\p{Greek}\d]`, "Ω1", "a"},
+		{`[\s\w]`, " \n\tA_9", "-ä"},
+		{`\t`, "\t", " "},
+		{`[ä-ü]`, "äöü", "aß"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			set, err := CompileRuneSet(tt.expr)
+			if !assert.Nil(t, err) {
+				return
+			}
+			for _, r := range tt.in {
+				assert.Truef(t, set.Contains(r), "%q should be in %s", r, tt.expr)
+			}
+			for _, r := range tt.out {
+				assert.Falsef(t, set.Contains(r), "%q should not be in %s", r, tt.expr)
+			}
+		})
+	}
+}
+
+func TestCompileRuneSet_Errors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{`[a-z`, `unexpected end of input at line 1, column 5, expected "]"`},
+		{`[z-a]`, `unexpected input at line 1, column 4, expected end of range, found "a"`},
+		{`[a-\d]`, `expected end of range, found "\\d"`},
+		{`\p{Klingon}`, `expected Unicode category or script, found "Klingon"`},
+		{`[\q]`, `expected escape sequence, found "\\q"`},
+		{`[a]b`, `expected end of rune set, found "b"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := CompileRuneSet(tt.expr)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestRuneSet_Operations(t *testing.T) {
+	a := assert.New(t)
+
+	letters := MustCompileRuneSet(`\p{L}`)
+	vowels := RuneSetOf('a', 'e', 'i', 'o', 'u', 'ä')
+	consonants := letters.Intersect(vowels.Negate())
+	a.True(consonants.Contains('b'))
+	a.False(consonants.Contains('a'))
+	a.False(consonants.Contains('ä'))
+	a.True(consonants.Contains('ß'))
+	a.False(consonants.Contains('1'))
+
+	digitsOrVowels := MustCompileRuneSet(`\d`).Union(vowels)
+	a.True(digitsOrVowels.Contains('1'))
+	a.True(digitsOrVowels.Contains('ä'))
+	a.False(digitsOrVowels.Contains('b'))
+}
+
+func TestRuneSet_ZeroValue(t *testing.T) {
+	a := assert.New(t)
+
+	var empty RuneSet
+	a.False(empty.Contains('a'))
+	a.False(empty.Contains('ä'))
+	a.True(empty.Negate().Contains('ä'))
+	a.True(empty.Union(RuneSetOf('ä')).Contains('ä'))
+	a.False(empty.Intersect(RuneSetOf('ä')).Contains('ä'))
+	a.Equal("", NewParser("äb").ReadSet(&empty))
+}
+
+func TestParser_ReadSet(t *testing.T) {
+	a := assert.New(t)
+
+	identifier := MustCompileRuneSet(`[\w\-]`)
+	p := NewParser(`my-key_1 = "value"`)
+	a.True(p.LookingAtSet(identifier))
+	a.Equal("my-key_1", p.ReadSet(identifier))
+	a.False(p.LookingAtSet(identifier))
+	p.SkipSet(MustCompileRuneSet(`[ =]`))
+	a.Equal(`"value"`, p.ReadWhile(MustCompileRuneSet(`[^\n]`).Contains))
+}
@@ -0,0 +1,105 @@
+package textparser
+
+// Span is a range of the input given by rune indices. End is the index right after the range.
+type Span struct {
+	Start int
+	End   int
+}
+
+// Len returns the number of runes in the span.
+func (x Span) Len() int {
+	return x.End - x.Start
+}
+
+// Spanned is a value along with the span of the input it was read from.
+type Spanned[T any] struct {
+	Value T
+	Span  Span
+}
+
+// ReadSpanned runs read and returns its value along with the span of input it consumed, e.g.
+//
+//	role, err := ReadSpanned(p, (*Parser).ReadWord)
+func ReadSpanned[T any](p *Parser, read func(p *Parser) (T, error)) (Spanned[T], error) {
+	start := p.position
+	value, err := read(p)
+	if err != nil {
+		return Spanned[T]{}, err
+	}
+	return Spanned[T]{Value: value, Span: Span{Start: start, End: p.position}}, nil
+}
+
+// ReadWordSpan is like ReadWord, but also returns the span of the word.
+func (x *Parser) ReadWordSpan() (Spanned[string], error) {
+	return ReadSpanned(x, (*Parser).ReadWord)
+}
+
+func (x *Parser) MustReadWordSpan() Spanned[string] {
+	value, err := x.ReadWordSpan()
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// ReadRestOfLineSpan is like ReadRestOfLine, but also returns the span of the line content.
+func (x *Parser) ReadRestOfLineSpan() (Spanned[string], error) {
+	return ReadSpanned(x, (*Parser).ReadRestOfLine)
+}
+
+func (x *Parser) MustReadRestOfLineSpan() Spanned[string] {
+	value, err := x.ReadRestOfLineSpan()
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// ReadIntSpan is like ReadInt, but also returns the span of the number.
+func (x *Parser) ReadIntSpan() (Spanned[int], error) {
+	return ReadSpanned(x, (*Parser).ReadInt)
+}
+
+func (x *Parser) MustReadIntSpan() Spanned[int] {
+	value, err := x.ReadIntSpan()
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// CapturedSpan returns the span since StartCapture was last called, see Captured.
+func (x *Parser) CapturedSpan() Span {
+	return Span{Start: x.captureStartPosition, End: x.position}
+}
+
+// ExtractSpan gets the input covered by span, see Extract. Unlike Extract, it accepts empty spans.
+func (x *Parser) ExtractSpan(span Span) (string, error) {
+	if span.Start == span.End && span.Start >= 0 && span.Start <= x.length() {
+		return "", nil
+	}
+	return x.Extract(span.Start, span.End)
+}
+
+func (x *Parser) MustExtractSpan(span Span) string {
+	value, err := x.ExtractSpan(span)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// SpanPositions converts the span to the positions of its start and end, for line and column information.
+func (x *Parser) SpanPositions(span Span) (start, end Position) {
+	return x.PositionAt(span.Start), x.PositionAt(span.End)
+}
+
+// Span returns the span of the submatch. It is {-1, -1} for groups that did not participate in the match.
+func (x Submatch) Span() Span {
+	return Span{Start: x.Start, End: x.End}
+}
+
+// Span returns the span of the capture.
+func (x Capture) Span() Span {
+	return Span{Start: x.Start, End: x.End}
+}
@@ -0,0 +1,62 @@
+package textparser
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParser_ReadSpan(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("role: Häuptling\nage: 42\n")
+	p.MustSkipString("role: ")
+	role := p.MustReadWordSpan()
+	a.Equal(Spanned[string]{Value: "Häuptling", Span: Span{Start: 6, End: 15}}, role)
+	a.Equal(9, role.Span.Len())
+	a.Equal("Häuptling", p.MustExtractSpan(role.Span))
+
+	start, end := p.SpanPositions(role.Span)
+	a.Equal(Position{Offset: 6, Line: 1, Column: 7, ByteOffset: 6}, start)
+	a.Equal(Position{Offset: 15, Line: 1, Column: 16, ByteOffset: 16}, end)
+
+	p.MustSkip(1).MustSkipString("age: ")
+	p.StartCapture()
+	age := p.MustReadIntSpan()
+	a.Equal(Spanned[int]{Value: 42, Span: Span{Start: 21, End: 23}}, age)
+	a.Equal(age.Span, p.CapturedSpan())
+
+	p.MustSkip(1)
+	line := p.MustReadRestOfLineSpan()
+	a.Equal(Span{Start: 24, End: 24}, line.Span)
+	a.Equal("", p.MustExtractSpan(line.Span))
+
+	_, err := p.ExtractSpan(Span{Start: 20, End: 40})
+	a.NotNil(err)
+}
+
+func TestReadSpanned(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("x = 1.5e3")
+	p.MustSkip(4)
+	value, err := ReadSpanned(p, (*Parser).ReadFloat)
+	a.Nil(err)
+	a.Equal(Spanned[float64]{Value: 1500, Span: Span{Start: 4, End: 9}}, value)
+
+	_, err = ReadSpanned(p, (*Parser).ReadFloat)
+	a.NotNil(err)
+}
+
+func TestSpanOf(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("key=value")
+	submatches := p.MustReadRegexpSubmatch(regexp.MustCompile(`(\w+)=(x)?(\w+)`))
+	a.Equal(Span{Start: 0, End: 3}, submatches[1].Span())
+	a.Equal(Span{Start: -1, End: -1}, submatches[2].Span())
+
+	p.Reset(Mark{}).BeginCapture("key").MustSkip(3)
+	a.Equal(Span{Start: 0, End: 3}, p.MustEndCapture().Span())
+}
@@ -0,0 +1,263 @@
+package textparser
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// StopSet is a precompiled set of strings to search for, see ReadToStopSet. Compile it once using NewStopSet and
+// reuse it across calls, it is safe for concurrent use. Compiling is more expensive than a single search, which is why
+// one-off searches like ReadToAnyString don't use it.
+//
+// The search finds the match starting first. If several patterns match at the same position, the one given first
+// wins. An empty pattern matches immediately.
+type StopSet struct {
+	patterns []string
+	// index of the first empty pattern, -1 if there is none
+	emptyPattern int
+	// length of the longest pattern in bytes
+	maxLength int
+	// the distinct first bytes of the patterns if they are all ASCII, used to skip ahead to the next possible match
+	firstBytes string
+
+	// Aho-Corasick automaton over the UTF-8 bytes of the patterns, with the failure links resolved into the
+	// transitions. State 0 is the root. It is nil for sets searched pattern by pattern, see patternStops.
+	transitions [][256]int32
+	// patterns ending in a state, including those reached by following failure links
+	outputs [][]int
+}
+
+// NewStopSet compiles patterns into a StopSet.
+func NewStopSet(patterns []string) *StopSet {
+	set := &StopSet{
+		patterns:     append([]string(nil), patterns...),
+		emptyPattern: -1,
+		transitions:  make([][256]int32, 1),
+		outputs:      make([][]int, 1),
+	}
+
+	// trie
+	for i, pattern := range set.patterns {
+		if pattern == "" {
+			if set.emptyPattern < 0 {
+				set.emptyPattern = i
+			}
+			continue
+		}
+		set.maxLength = max(set.maxLength, len(pattern))
+
+		state := int32(0)
+		for j := 0; j < len(pattern); j++ {
+			next := set.transitions[state][pattern[j]]
+			if next == 0 {
+				next = int32(len(set.transitions))
+				set.transitions = append(set.transitions, [256]int32{})
+				set.outputs = append(set.outputs, nil)
+				set.transitions[state][pattern[j]] = next
+			}
+			state = next
+		}
+		set.outputs[state] = append(set.outputs[state], i)
+	}
+
+	var first strings.Builder
+	for b := 0; b < 256; b++ {
+		if set.transitions[0][b] == 0 {
+			continue
+		}
+		if b >= utf8.RuneSelf {
+			first.Reset()
+			break
+		}
+		first.WriteByte(byte(b))
+	}
+	set.firstBytes = first.String()
+
+	// failure links in breadth-first order, so the failure state of a state is complete before it is used
+	var (
+		fail  = make([]int32, len(set.transitions))
+		queue = make([]int32, 0, len(set.transitions))
+	)
+	for b := 0; b < 256; b++ {
+		if next := set.transitions[0][b]; next != 0 {
+			queue = append(queue, next)
+		}
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		set.outputs[state] = append(set.outputs[state], set.outputs[fail[state]]...)
+
+		for b := 0; b < 256; b++ {
+			next := set.transitions[state][b]
+			if next == 0 {
+				set.transitions[state][b] = set.transitions[fail[state]][b]
+				continue
+			}
+			fail[next] = set.transitions[fail[state]][b]
+			queue = append(queue, next)
+		}
+	}
+
+	return set
+}
+
+// patternStops creates an uncompiled set, which searches for each of patterns separately. It is cheap to create, but
+// a search takes time proportional to the number of patterns.
+func patternStops(patterns []string) *StopSet {
+	set := &StopSet{patterns: patterns, emptyPattern: -1}
+	for i, pattern := range patterns {
+		if pattern == "" && set.emptyPattern < 0 {
+			set.emptyPattern = i
+		}
+	}
+	return set
+}
+
+// Patterns returns the patterns of the set in their original order.
+func (x *StopSet) Patterns() []string {
+	return append([]string(nil), x.patterns...)
+}
+
+// find returns the byte offset of the first match in s and the index of the matching pattern, or -1 and -1.
+func (x *StopSet) find(s string) (offset, pattern int) {
+	if x.emptyPattern >= 0 {
+		for i := 0; i < x.emptyPattern; i++ {
+			if strings.HasPrefix(s, x.patterns[i]) {
+				return 0, i
+			}
+		}
+		return 0, x.emptyPattern
+	}
+
+	offset, pattern = -1, -1
+	if x.transitions == nil {
+		for i, candidate := range x.patterns {
+			if offset == 0 {
+				break
+			}
+			// only a match starting before the best one is of interest
+			limit := len(s)
+			if offset >= 0 {
+				limit = min(limit, offset-1+len(candidate))
+			}
+			if start := strings.Index(s[:limit], candidate); start >= 0 {
+				offset, pattern = start, i
+			}
+		}
+		return offset, pattern
+	}
+
+	state := int32(0)
+	for i := 0; i < len(s); i++ {
+		// no match found later can start before the best one
+		if offset >= 0 && i-offset >= x.maxLength {
+			break
+		}
+
+		// nothing can match before the next first byte of a pattern
+		if state == 0 && x.firstBytes != "" {
+			next := x.indexFirstByte(s[i:])
+			if next < 0 {
+				break
+			}
+			i += next
+		}
+
+		state = x.transitions[state][s[i]]
+		for _, candidate := range x.outputs[state] {
+			start := i + 1 - len(x.patterns[candidate])
+			if offset < 0 || start < offset || (start == offset && candidate < pattern) {
+				offset, pattern = start, candidate
+			}
+		}
+	}
+	return offset, pattern
+}
+
+// indexFirstByte returns the offset of the first byte in s that can start a match, or -1.
+func (x *StopSet) indexFirstByte(s string) int {
+	if len(x.firstBytes) == 1 {
+		return strings.IndexByte(s, x.firstBytes[0])
+	}
+	return strings.IndexAny(s, x.firstBytes)
+}
+
+// findStop returns the rune index of the next match of set at or after the current position and the index of the
+// matching pattern. It returns EndOfInputError if there is none.
+func (x *Parser) findStop(set *StopSet) (int, int, error) {
+	var (
+		base            = x.byteOffset(x.position)
+		offset, pattern = set.find(x.input[base:])
+	)
+	if pattern < 0 {
+		return 0, -1, EndOfInputError{}
+	}
+	return x.position + utf8.RuneCountInString(x.input[base:base+offset]), pattern, nil
+}
+
+// ReadToStopSet reads up to the next match of set, which is not consumed, and returns the index of the matching
+// pattern. Searching all patterns takes a single pass over the input, so this is the way to go for many patterns or
+// when searching for the same patterns repeatedly.
+func (x *Parser) ReadToStopSet(set *StopSet) (string, int, error) {
+	end, pattern, err := x.findStop(set)
+	if err != nil {
+		return "", -1, x.NewParseError(EndOfInput, "one of "+quoteAll(set.patterns), "", err)
+	}
+	if err := x.checkTokenLength(end - x.position); err != nil {
+		return "", -1, err
+	}
+	content := x.slice(x.position, end)
+	x.position = end
+	return content, pattern, nil
+}
+
+func (x *Parser) MustReadToStopSet(set *StopSet) (string, int) {
+	content, pattern, err := x.ReadToStopSet(set)
+	if err != nil {
+		panic(err)
+	}
+	return content, pattern
+}
+
+// ReadToStopSetOrEnd is like ReadToStopSet, but reads the rest of the input if set does not match. The pattern index
+// is -1 in that case.
+func (x *Parser) ReadToStopSetOrEnd(set *StopSet) (string, int, error) {
+	end, pattern, err := x.findStop(set)
+	if err != nil {
+		end = x.length()
+	}
+	if err := x.checkTokenLength(end - x.position); err != nil {
+		return "", -1, err
+	}
+	content := x.slice(x.position, end)
+	x.position = end
+	return content, pattern, nil
+}
+
+func (x *Parser) MustReadToStopSetOrEnd(set *StopSet) (string, int) {
+	content, pattern, err := x.ReadToStopSetOrEnd(set)
+	if err != nil {
+		panic(err)
+	}
+	return content, pattern
+}
+
+// SkipToStopSet skips up to the next match of set, which is not consumed, and returns the index of the matching
+// pattern.
+func (x *Parser) SkipToStopSet(set *StopSet) (int, error) {
+	end, pattern, err := x.findStop(set)
+	if err != nil {
+		return -1, x.NewParseError(EndOfInput, "one of "+quoteAll(set.patterns), "", err)
+	}
+	x.position = end
+	return pattern, nil
+}
+
+func (x *Parser) MustSkipToStopSet(set *StopSet) *Parser {
+	_, err := x.SkipToStopSet(set)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
@@ -0,0 +1,178 @@
+package textparser
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStopSet_find(t *testing.T) {
+	tests := []struct {
+		name        string
+		patterns    []string
+		input       string
+		wantOffset  int
+		wantPattern int
+	}{
+		{
+			name:        "no match",
+			patterns:    []string{"x", "yz"},
+			input:       "abc y",
+			wantOffset:  -1,
+			wantPattern: -1,
+		},
+		{
+			name:        "first start wins",
+			patterns:    []string{"cd", "bcde"},
+			input:       "abcdef",
+			wantOffset:  1,
+			wantPattern: 1,
+		},
+		{
+			name:        "same start, first pattern wins",
+			patterns:    []string{"abc", "ab"},
+			input:       "xabc",
+			wantOffset:  1,
+			wantPattern: 0,
+		},
+		{
+			name:        "same start, shorter first pattern wins",
+			patterns:    []string{"ab", "abc"},
+			input:       "xabc",
+			wantOffset:  1,
+			wantPattern: 0,
+		},
+		{
+			name:        "overlapping via failure link",
+			patterns:    []string{"she", "he", "hers"},
+			input:       "ushers",
+			wantOffset:  1,
+			wantPattern: 0,
+		},
+		{
+			name:        "pattern at end of input",
+			patterns:    []string{"end"},
+			input:       "the end",
+			wantOffset:  4,
+			wantPattern: 0,
+		},
+		{
+			name:        "empty pattern",
+			patterns:    []string{"a", ""},
+			input:       "ba",
+			wantOffset:  0,
+			wantPattern: 1,
+		},
+		{
+			name:        "empty pattern loses against earlier match",
+			patterns:    []string{"b", ""},
+			input:       "ba",
+			wantOffset:  0,
+			wantPattern: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offset, pattern := NewStopSet(tt.patterns).find(tt.input)
+			assert.Equal(t, tt.wantOffset, offset)
+			assert.Equal(t, tt.wantPattern, pattern)
+
+			// searching pattern by pattern gives the same result
+			offset, pattern = patternStops(tt.patterns).find(tt.input)
+			assert.Equal(t, tt.wantOffset, offset, "patternStops")
+			assert.Equal(t, tt.wantPattern, pattern, "patternStops")
+		})
+	}
+}
+
+func TestParser_ReadToStopSet(t *testing.T) {
+	a := assert.New(t)
+
+	set := NewStopSet([]string{"→", "ß", "\n"})
+	a.Equal([]string{"→", "ß", "\n"}, set.Patterns())
+
+	p := NewParser("größe → maß\nrest")
+	content, pattern := p.MustReadToStopSet(set)
+	a.Equal("grö", content)
+	a.Equal(1, pattern)
+	a.True(p.LookingAtString("ße"))
+
+	p.MustSkip(1)
+	content, pattern = p.MustReadToStopSet(set)
+	a.Equal("e ", content)
+	a.Equal(0, pattern)
+	a.Equal(6, p.CurrentIndex())
+
+	p.MustSkip(1)
+	a.Equal(10, p.MustSkipToStopSet(set).CurrentIndex())
+	p.MustSkip(1)
+	pattern, err := p.SkipToStopSet(set)
+	a.Nil(err)
+	a.Equal(2, pattern)
+
+	p.MustSkip(1)
+	_, _, err = p.ReadToStopSet(set)
+	a.ErrorIs(err, EndOfInputError{})
+	a.Equal("rest", p.Remaining())
+
+	content, pattern = p.MustReadToStopSetOrEnd(set)
+	a.Equal("rest", content)
+	a.Equal(-1, pattern)
+	a.True(p.IsExhausted())
+}
+
+func TestParser_SkipToAnyString(t *testing.T) {
+	a := assert.New(t)
+
+	p := NewParser("key = value; other")
+	pattern, err := p.SkipToAnyString([]string{";", "="})
+	a.Nil(err)
+	a.Equal(1, pattern)
+	a.True(p.LookingAtString("= value"))
+
+	a.True(p.MustSkipToAnyString([]string{";"}).LookingAtString("; other"))
+
+	_, err = p.SkipToAnyString([]string{"#"})
+	a.ErrorIs(err, EndOfInputError{})
+	a.True(p.LookingAtString("; other"))
+}
+
+func benchmarkStops(b *testing.B, patternCount int, read func(p *Parser, patterns []string)) {
+	patterns := make([]string, patternCount)
+	for i := range patterns {
+		patterns[i] = "<stop" + strconv.Itoa(i) + ">"
+	}
+	input := strings.Repeat("lorem ipsum dolor sit amet, ", 100*1024/28) + patterns[patternCount-1]
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		read(NewParser(input), patterns)
+	}
+}
+
+func BenchmarkParser_ReadToAnyString(b *testing.B) {
+	for _, count := range []int{1, 10} {
+		b.Run(strconv.Itoa(count), func(b *testing.B) {
+			benchmarkStops(b, count, func(p *Parser, patterns []string) {
+				p.MustReadToAnyString(patterns)
+			})
+		})
+	}
+}
+
+func BenchmarkParser_ReadToStopSet(b *testing.B) {
+	for _, count := range []int{1, 10} {
+		b.Run(strconv.Itoa(count), func(b *testing.B) {
+			var set *StopSet
+			benchmarkStops(b, count, func(p *Parser, patterns []string) {
+				if set == nil {
+					set = NewStopSet(patterns)
+				}
+				p.MustReadToStopSet(set)
+			})
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package textparser
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Unmarshaler is implemented by types that parse themselves from the current position of the parser.
+type Unmarshaler interface {
+	UnmarshalTextParser(p *Parser) error
+}
+
+// fieldOptions is the parsed content of a `textparser` struct tag.
+type fieldOptions struct {
+	kind     string
+	prefix   string
+	suffix   string
+	sep      string
+	optional bool
+}
+
+// Unmarshal parses input into the struct v points to, see Parser.Unmarshal. The whole input except for trailing
+// whitespace must be consumed.
+func Unmarshal(input string, v any) error {
+	p := NewParser(input)
+	err := p.Unmarshal(v)
+	if err != nil {
+		return err
+	}
+
+	_ = p.SkipAnyWhitespaces()
+	if p.HasMore() {
+		return p.NewParseError(UnexpectedInput, "end of input", p.GetNextMax(10), nil)
+	}
+	return nil
+}
+
+// Unmarshal parses the input at the current position into the struct v points to. Exported fields are read in
+// order, their grammar is declared in a `textparser` struct tag containing a comma separated list of a kind and
+// options:
+//
+//	int, float      a number
+//	word            text up to the next space or newline (default for strings)
+//	line            the rest of the line, the line break is consumed but not part of the value
+//	quoted          a double quoted string with the escapes of Go, see strconv.Unquote
+//	rest            the rest of the input
+//	prefix='…'      literal text before the value
+//	suffix='…'      literal text after the value
+//	sep='…'         separator between the elements of a slice
+//	optional        leave the field empty if it can't be parsed
+//
+// Without a kind, it is derived from the field type. Structs are parsed recursively, slices repeat their element
+// grammar as long as it matches. Types implementing Unmarshaler parse themselves, types implementing
+// encoding.TextUnmarshaler get the text read according to the kind. Use the tag `textparser:"-"` to skip a field.
+//
+// On failure, the parser is reset to where it started.
+func (x *Parser) Unmarshal(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return x.NewParseError(InvalidArgument, "", "", fmt.Errorf("can't unmarshal into %T, need a pointer to a struct", v))
+	}
+	return x.Attempt(func(p *Parser) error {
+		return p.unmarshalStruct(rv.Elem())
+	})
+}
+
+func (x *Parser) MustUnmarshal(v any) *Parser {
+	err := x.Unmarshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+func (x *Parser) unmarshalStruct(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("textparser")
+		if tag == "-" {
+			continue
+		}
+
+		opts, err := parseFieldOptions(tag)
+		if err != nil {
+			return fmt.Errorf("field %s: invalid tag: %w", field.Name, err)
+		}
+		err = x.unmarshalField(v.Field(i), opts)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+func (x *Parser) unmarshalField(v reflect.Value, opts fieldOptions) error {
+	err := x.Attempt(func(p *Parser) error {
+		if opts.prefix != "" {
+			err := p.SkipString(opts.prefix)
+			if err != nil {
+				return err
+			}
+		}
+
+		var err error
+		if v.Kind() == reflect.Slice && !hasUnmarshalHook(v) {
+			err = p.unmarshalSlice(v, opts)
+		} else {
+			err = p.unmarshalValue(v, opts.kind)
+		}
+		if err != nil {
+			return err
+		}
+
+		if opts.suffix != "" {
+			return p.SkipString(opts.suffix)
+		}
+		return nil
+	})
+	if err != nil && opts.optional {
+		v.SetZero()
+		return nil
+	}
+	return err
+}
+
+func (x *Parser) unmarshalSlice(v reflect.Value, opts fieldOptions) error {
+	values := reflect.MakeSlice(v.Type(), 0, 0)
+	for {
+		mark := x.Mark()
+		if values.Len() > 0 && opts.sep != "" {
+			if x.SkipString(opts.sep) != nil {
+				break
+			}
+		}
+
+		elem := reflect.New(v.Type().Elem()).Elem()
+		err := x.Attempt(func(p *Parser) error {
+			return p.unmarshalValue(elem, opts.kind)
+		})
+		if err != nil {
+			x.Reset(mark)
+			break
+		}
+		values = reflect.Append(values, elem)
+
+		// stop after an empty element, the next one would be empty at the same index again
+		if x.CurrentIndex() == mark.Index() {
+			break
+		}
+	}
+	v.Set(values)
+	return nil
+}
+
+func (x *Parser) unmarshalValue(v reflect.Value, kind string) error {
+	if v.CanAddr() {
+		switch hook := v.Addr().Interface().(type) {
+		case Unmarshaler:
+			return hook.UnmarshalTextParser(x)
+		case encoding.TextUnmarshaler:
+			text, err := x.readKind(kind, "word")
+			if err != nil {
+				return err
+			}
+			return hook.UnmarshalText([]byte(fmt.Sprint(text)))
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		err := x.unmarshalValue(elem.Elem(), kind)
+		if err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+
+	case reflect.Struct:
+		return x.unmarshalStruct(v)
+
+	case reflect.String:
+		value, err := x.readKind(kind, "word")
+		if err != nil {
+			return err
+		}
+		v.SetString(fmt.Sprint(value))
+		return nil
+
+	case reflect.Bool:
+		start := x.CurrentIndex()
+		value, err := x.readKind(kind, "word")
+		if err != nil {
+			return err
+		}
+		b, err := strconv.ParseBool(fmt.Sprint(value))
+		if err != nil {
+			return x.NewParseErrorAt(start, UnexpectedInput, "boolean", fmt.Sprint(value), err)
+		}
+		v.SetBool(b)
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		start := x.CurrentIndex()
+		value, err := x.readKind(kind, "int")
+		if err != nil {
+			return err
+		}
+		i, ok := value.(int)
+		if !ok {
+			return x.NewParseErrorAt(start, InvalidArgument, "", "", fmt.Errorf("can't store %s in %s", kind, v.Type()))
+		}
+		if v.OverflowInt(int64(i)) {
+			return x.NewParseErrorAt(start, NumberOverflow, v.Type().String(), strconv.Itoa(i), nil)
+		}
+		v.SetInt(int64(i))
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		start := x.CurrentIndex()
+		value, err := x.readKind(kind, "int")
+		if err != nil {
+			return err
+		}
+		i, ok := value.(int)
+		if !ok {
+			return x.NewParseErrorAt(start, InvalidArgument, "", "", fmt.Errorf("can't store %s in %s", kind, v.Type()))
+		}
+		if i < 0 || v.OverflowUint(uint64(i)) {
+			return x.NewParseErrorAt(start, NumberOverflow, v.Type().String(), strconv.Itoa(i), nil)
+		}
+		v.SetUint(uint64(i))
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		start := x.CurrentIndex()
+		value, err := x.readKind(kind, "float")
+		if err != nil {
+			return err
+		}
+		f, ok := value.(float64)
+		if !ok {
+			return x.NewParseErrorAt(start, InvalidArgument, "", "", fmt.Errorf("can't store %s in %s", kind, v.Type()))
+		}
+		v.SetFloat(f)
+		return nil
+	}
+
+	return x.NewParseError(InvalidArgument, "", "", fmt.Errorf("can't unmarshal into %s", v.Type()))
+}
+
+// readKind reads a value according to kind, or defaultKind if kind is empty. Numbers are returned as int or float64,
+// everything else as string.
+func (x *Parser) readKind(kind, defaultKind string) (any, error) {
+	if kind == "" {
+		kind = defaultKind
+	}
+	switch kind {
+	case "int":
+		return x.ReadInt()
+	case "float":
+		return x.ReadFloat()
+	case "word":
+		return x.ReadWord()
+	case "line":
+		value, err := x.ReadRestOfLine()
+		if err != nil {
+			return "", err
+		}
+		if x.LookingAtRune('\n') {
+			x.MustSkip(1)
+		}
+		return value, nil
+	case "quoted":
+		return x.readQuoted()
+	case "rest":
+		return x.ReadRestOfInput()
+	}
+	return nil, x.NewParseError(InvalidArgument, "", "", fmt.Errorf("unknown kind %q", kind))
+}
+
+func hasUnmarshalHook(v reflect.Value) bool {
+	if !v.CanAddr() {
+		return false
+	}
+	switch v.Addr().Interface().(type) {
+	case Unmarshaler, encoding.TextUnmarshaler:
+		return true
+	}
+	return false
+}
+
+// parseFieldOptions parses a struct tag like `int,prefix='Game ',sep=', '`.
+func parseFieldOptions(tag string) (fieldOptions, error) {
+	var (
+		opts fieldOptions
+		p    = NewParser(tag)
+	)
+	for p.HasMore() {
+		name, err := p.ReadToAnyStringOrEnd([]string{",", "="})
+		if err != nil {
+			return opts, err
+		}
+
+		value := ""
+		hasValue := p.LookingAtRune('=')
+		if hasValue {
+			p.MustSkip(1)
+			if p.LookingAtRune('\'') {
+				value, err = p.ReadToMatchingRuneEscapedSkipDelims('\'', '\'', '\\')
+			} else {
+				value, err = p.ReadToAnyStringOrEnd([]string{","})
+			}
+			if err != nil {
+				return opts, err
+			}
+		}
+
+		switch {
+		case name == "prefix" && hasValue:
+			opts.prefix = value
+		case name == "suffix" && hasValue:
+			opts.suffix = value
+		case name == "sep" && hasValue:
+			opts.sep = value
+		case name == "optional" && !hasValue:
+			opts.optional = true
+		case (name == "int" || name == "float" || name == "word" || name == "line" || name == "quoted" || name == "rest") && !hasValue:
+			opts.kind = name
+		default:
+			return opts, fmt.Errorf("unknown option %q", name)
+		}
+
+		if p.LookingAtRune(',') {
+			p.MustSkip(1)
+		}
+	}
+	return opts, nil
+}
@@ -0,0 +1,100 @@
+package textparser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type upperName string
+
+func (x *upperName) UnmarshalText(text []byte) error {
+	*x = upperName(strings.ToUpper(string(text)))
+	return nil
+}
+
+type version struct {
+	Major, Minor int
+}
+
+func (x *version) UnmarshalTextParser(p *Parser) error {
+	return p.Scanf("v%d.%d", &x.Major, &x.Minor)
+}
+
+func TestUnmarshal_KeyValue(t *testing.T) {
+	a := assert.New(t)
+
+	var person struct {
+		Name string `textparser:"line,prefix='name: '"`
+		Role string `textparser:"line,prefix='role: '"`
+		OS   string `textparser:"line,prefix='os: '"`
+	}
+	err := Unmarshal("name: Tom Williams\nrole: CEO\nos: Debian Linux\n", &person)
+	a.Nil(err)
+	a.Equal("Tom Williams", person.Name)
+	a.Equal("CEO", person.Role)
+	a.Equal("Debian Linux", person.OS)
+}
+
+func TestUnmarshal_Record(t *testing.T) {
+	a := assert.New(t)
+
+	type player struct {
+		Name  upperName `textparser:"word"`
+		Score uint8     `textparser:"prefix=' ('"`
+		Bonus *float64  `textparser:"optional,prefix=' +'"`
+		Rest  string    `textparser:"-"`
+	}
+	var game struct {
+		ID      int      `textparser:"prefix='Game ',suffix=': '"`
+		Version version  `textparser:"suffix=' '"`
+		Numbers []int    `textparser:"sep=', '"`
+		Title   string   `textparser:"quoted,prefix=' '"`
+		Final   bool     `textparser:"optional,prefix=' final='"`
+		Players []player `textparser:"prefix=' [',suffix=']',sep=') '"`
+	}
+
+	err := Unmarshal(`Game 22: v1.3 13, 14, 216 "the \"big\" one\\" [tom (7 +1.5) ann (12]`, &game)
+	a.Nil(err)
+	a.Equal(22, game.ID)
+	a.Equal(version{1, 3}, game.Version)
+	a.Equal([]int{13, 14, 216}, game.Numbers)
+	a.Equal(`the "big" one\`, game.Title)
+	a.False(game.Final)
+	if a.Len(game.Players, 2) {
+		a.Equal(upperName("TOM"), game.Players[0].Name)
+		a.Equal(uint8(7), game.Players[0].Score)
+		a.Equal(1.5, *game.Players[0].Bonus)
+		a.Equal(upperName("ANN"), game.Players[1].Name)
+		a.Equal(uint8(12), game.Players[1].Score)
+		a.Nil(game.Players[1].Bonus)
+	}
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	a := assert.New(t)
+
+	var small struct {
+		Value int8 `textparser:"prefix='value='"`
+	}
+	err := Unmarshal("value=300", &small)
+	var parseErr *ParseError
+	a.True(errors.As(err, &parseErr))
+	a.Equal(NumberOverflow, parseErr.Kind)
+	a.Equal(6, parseErr.Position.Offset)
+	a.ErrorContains(err, "field Value: number overflow")
+
+	a.ErrorContains(Unmarshal("value=3 more", &small), `expected end of input, found "more"`)
+
+	var badTag struct {
+		Value int `textparser:"integer"`
+	}
+	a.ErrorContains(Unmarshal("3", &badTag), `field Value: invalid tag: unknown option "integer"`)
+	a.ErrorContains(Unmarshal("3", small), "need a pointer to a struct")
+
+	p := NewParser("value=x")
+	a.Error(p.Unmarshal(&small))
+	a.Equal(0, p.CurrentIndex())
+}